@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+)
+
+// createBackupCard creates the export and import section of the account tab
+func (g *GUI) createBackupCard() fyne.CanvasObject {
+	heading := widget.NewLabelWithStyle(
+		"Backup",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	infoLabel := widget.NewLabel("Export all your pastes, folders and tags to an archive encrypted with a\n" +
+		"passphrase of your choice. Importing an archive adds its pastes to this\n" +
+		"account, skipping any whose content you already have.")
+
+	exportButton := widget.NewButton("Export Archive", func() {
+		g.showExportDialog()
+	})
+	exportButton.Importance = widget.HighImportance
+
+	importButton := widget.NewButton("Import Archive", func() {
+		g.showImportDialog()
+	})
+
+	plaintextButton := widget.NewButtonWithIcon("Export Unencrypted...", theme.WarningIcon(), func() {
+		g.showPlaintextExportDialog()
+	})
+	plaintextButton.Importance = widget.DangerImportance
+
+	return container.NewVBox(
+		heading,
+		widget.NewSeparator(),
+		container.NewPadded(infoLabel),
+		container.NewPadded(container.NewHBox(exportButton, importButton, plaintextButton)),
+	)
+}
+
+// showExportDialog asks for a passphrase and a file, then exports to it
+func (g *GUI) showExportDialog() {
+	passphraseEntry := widget.NewPasswordEntry()
+	passphraseEntry.SetPlaceHolder(fmt.Sprintf("At least %d characters", core.MinArchivePassphraseLength))
+
+	confirmEntry := widget.NewPasswordEntry()
+	confirmEntry.SetPlaceHolder("Repeat the passphrase")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Passphrase", passphraseEntry),
+		widget.NewFormItem("Confirm", confirmEntry),
+	}
+
+	dialog.ShowForm("Export Archive", "Choose File", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+
+		passphrase := passphraseEntry.Text
+		if passphrase != confirmEntry.Text {
+			dialog.ShowError(fmt.Errorf("passphrases do not match"), g.mainWindow)
+			return
+		}
+		if len(passphrase) < core.MinArchivePassphraseLength {
+			dialog.ShowError(fmt.Errorf("the passphrase must be at least %d characters", core.MinArchivePassphraseLength), g.mainWindow)
+			return
+		}
+
+		save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, g.mainWindow)
+				return
+			}
+			if writer == nil {
+				return
+			}
+
+			progress := dialog.NewProgressInfinite("Export Archive", "Encrypting your pastes...", g.mainWindow)
+			progress.Show()
+
+			go func() {
+				count, err := g.pasteApp.ExportArchive(writer, passphrase)
+				if closeErr := writer.Close(); err == nil {
+					err = closeErr
+				}
+				progress.Hide()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("export failed: %v", err), g.mainWindow)
+					return
+				}
+
+				dialog.ShowInformation("Export Archive", fmt.Sprintf("Exported %d pastes.\nKeep the passphrase safe; the archive can't be opened without it.", count), g.mainWindow)
+			}()
+		}, g.mainWindow)
+		save.SetFileName("pastepal-backup.ppa")
+		save.Show()
+	}, g.mainWindow)
+}
+
+// showImportDialog asks for an archive and its passphrase, then imports it
+func (g *GUI) showImportDialog() {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, g.mainWindow)
+			return
+		}
+		if reader == nil {
+			return
+		}
+
+		passphraseEntry := widget.NewPasswordEntry()
+		items := []*widget.FormItem{
+			widget.NewFormItem("Passphrase", passphraseEntry),
+		}
+
+		dialog.ShowForm("Import Archive", "Import", "Cancel", items, func(submitted bool) {
+			if !submitted {
+				reader.Close()
+				return
+			}
+
+			progress := dialog.NewProgressInfinite("Import Archive", "Importing pastes...", g.mainWindow)
+			progress.Show()
+
+			go func() {
+				defer reader.Close()
+
+				result, err := g.pasteApp.ImportArchive(reader, passphraseEntry.Text)
+				progress.Hide()
+				if err != nil {
+					if result != nil {
+						err = fmt.Errorf("%v\n%s", err, importSummary(result))
+					}
+					dialog.ShowError(fmt.Errorf("import failed: %v", err), g.mainWindow)
+					return
+				}
+
+				g.loadFolders()
+				dialog.ShowInformation("Import Archive", importSummary(result)+".\nRefresh My Pastes to see them.", g.mainWindow)
+			}()
+		}, g.mainWindow)
+	}, g.mainWindow)
+}
+
+// Plaintext export format options, and the file extension of each
+var plaintextFormats = []struct {
+	label  string
+	format core.PlaintextFormat
+	ext    string
+}{
+	{"Folder of files", core.PlaintextDirectory, ""},
+	{"JSON document", core.PlaintextJSON, ".json"},
+	{"Markdown file", core.PlaintextMarkdown, ".md"},
+}
+
+// showPlaintextExportDialog asks which pastes to export and how, has the
+// user confirm that the export is unencrypted, and then exports them to a
+// chosen folder
+func (g *GUI) showPlaintextExportDialog() {
+	labels := make([]string, len(plaintextFormats))
+	for i, f := range plaintextFormats {
+		labels[i] = f.label
+	}
+	formatSelect := widget.NewSelect(labels, nil)
+	formatSelect.SetSelectedIndex(0)
+
+	folderSelect := newFolderPicker("All folders", g.folders)
+
+	tagSelect := widget.NewSelect(append([]string{allTagsLabel}, g.pasteApp.CollectTags(g.pastes)...), nil)
+	tagSelect.SetSelectedIndex(0)
+
+	sinceEntry := widget.NewEntry()
+	sinceEntry.SetPlaceHolder("YYYY-MM-DD, optional")
+	untilEntry := widget.NewEntry()
+	untilEntry.SetPlaceHolder("YYYY-MM-DD, optional")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Format", formatSelect),
+		widget.NewFormItem("Folder", folderSelect.Select),
+		widget.NewFormItem("Tag", tagSelect),
+		widget.NewFormItem("Created from", sinceEntry),
+		widget.NewFormItem("Created until", untilEntry),
+	}
+
+	dialog.ShowForm("Export Unencrypted", "Next", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+
+		filter := core.PasteFilter{FolderID: folderSelect.FolderID()}
+		if tagSelect.SelectedIndex() > 0 {
+			filter.Tag = tagSelect.Selected
+		}
+
+		var err error
+		if filter.Since, err = parseExportDate(sinceEntry.Text, false); err != nil {
+			dialog.ShowError(err, g.mainWindow)
+			return
+		}
+		if filter.Until, err = parseExportDate(untilEntry.Text, true); err != nil {
+			dialog.ShowError(err, g.mainWindow)
+			return
+		}
+
+		g.confirmPlaintextExport(plaintextFormats[formatSelect.SelectedIndex()].format, filter)
+	}, g.mainWindow)
+}
+
+// confirmPlaintextExport warns that the export is unencrypted and only goes
+// on once the user types the confirmation word
+func (g *GUI) confirmPlaintextExport(format core.PlaintextFormat, filter core.PasteFilter) {
+	warning := widget.NewLabel("These files are NOT encrypted. Anyone and anything that can read\n" +
+		"them can read your pastes, and deleted files may stay recoverable\n" +
+		"from disk or backups. Only export what the receiving tool needs.")
+	warning.TextStyle = fyne.TextStyle{Bold: true}
+
+	confirmEntry := widget.NewEntry()
+	confirmEntry.SetPlaceHolder(plaintextConfirmation)
+
+	content := container.NewVBox(
+		container.NewHBox(widget.NewIcon(theme.WarningIcon()), warning),
+		widget.NewLabel(fmt.Sprintf("Type %q to continue:", plaintextConfirmation)),
+		confirmEntry,
+	)
+
+	confirm := dialog.NewCustomConfirm("Export Unencrypted", "Choose Folder", "Cancel", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if strings.TrimSpace(confirmEntry.Text) != plaintextConfirmation {
+			dialog.ShowError(fmt.Errorf("export cancelled: type %q to confirm", plaintextConfirmation), g.mainWindow)
+			return
+		}
+
+		dialog.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, g.mainWindow)
+				return
+			}
+			if dir == nil {
+				return
+			}
+
+			name := "pastepal-export-" + time.Now().Format("2006-01-02-150405")
+			for _, f := range plaintextFormats {
+				if f.format == format {
+					name += f.ext
+				}
+			}
+			path := filepath.Join(dir.Path(), name)
+
+			progress := dialog.NewProgressInfinite("Export Unencrypted", "Decrypting your pastes...", g.mainWindow)
+			progress.Show()
+
+			go func() {
+				count, err := g.pasteApp.ExportPlaintext(path, core.PlaintextExportOptions{
+					Format:    format,
+					Filter:    filter,
+					Confirmed: true,
+				})
+				progress.Hide()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("export failed: %v", err), g.mainWindow)
+					return
+				}
+
+				dialog.ShowInformation("Export Unencrypted", fmt.Sprintf("Exported %d pastes to\n%s\n\nDelete them when you no longer need them.", count, path), g.mainWindow)
+			}()
+		}, g.mainWindow)
+	}, g.mainWindow)
+	confirm.Show()
+}
+
+// parseExportDate parses a date given as YYYY-MM-DD in local time. With
+// endOfDay, the last moment of that day is returned, so the day is included
+// in a range ending there. An empty date gives the zero time.
+func parseExportDate(date string, endOfDay bool) (time.Time, error) {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", date)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
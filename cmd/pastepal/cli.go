@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/api"
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+)
+
+const cliUsage = `Usage:
+  pastepal                   start the app
+  pastepal new [-title title] [-public] [-language name]
+        [-format plain|code|markdown] [-tags a,b]
+        [-redact | -allow-secrets] [-no-compress]
+                             create a paste from stdin and print its ID,
+                             and its share link when public. Public
+                             pastes are checked for secrets first.
+                             -no-compress is for input mixing secrets
+                             with text someone else controls.
+  pastepal get <id or link>  print a paste's content to stdout
+  pastepal export <file>     save all pastes to an encrypted archive
+  pastepal import <file>     add the pastes of an archive to your account
+  pastepal export-plain [-format dir|json|markdown] [-tag tag]
+        [-folder path] [-since yyyy-mm-dd] [-until yyyy-mm-dd] <path>
+                             save pastes UNENCRYPTED for other tools`
+
+// plaintextConfirmation must be typed to confirm a plaintext export
+const plaintextConfirmation = "unencrypted"
+
+// cli runs subcommands from a terminal. Prompts go to stderr, and answers
+// are read from stdin unless it carries a paste.
+type cli struct {
+	app   *core.PastePalApp
+	input *bufio.Reader
+	// File descriptor secrets are read from without echo
+	secretFD int
+	// Where command output goes. The app's log messages go to stderr.
+	out io.Writer
+}
+
+// runCommand runs the subcommand named by args instead of the GUI, writing
+// its output to out
+func runCommand(app *core.PastePalApp, args []string, out io.Writer) error {
+	c := &cli{app: app, input: bufio.NewReader(os.Stdin), secretFD: int(os.Stdin.Fd()), out: out}
+
+	switch args[0] {
+	case "new":
+		return c.newPaste(args[1:])
+	case "get":
+		if len(args) != 2 {
+			return errors.New(cliUsage)
+		}
+		return c.getPaste(args[1])
+	case "export":
+		if len(args) != 2 {
+			return errors.New(cliUsage)
+		}
+		return c.export(args[1])
+	case "import":
+		if len(args) != 2 {
+			return errors.New(cliUsage)
+		}
+		return c.importArchive(args[1])
+	case "export-plain":
+		return c.exportPlaintext(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprintln(c.out, cliUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], cliUsage)
+	}
+}
+
+// export logs in and writes the user's pastes to an archive at path
+func (c *cli) export(path string) error {
+	if err := c.login(); err != nil {
+		return err
+	}
+
+	passphrase, err := c.readSecret("Archive passphrase: ")
+	if err != nil {
+		return err
+	}
+	confirm, err := c.readSecret("Repeat passphrase: ")
+	if err != nil {
+		return err
+	}
+	if passphrase != confirm {
+		return errors.New("the passphrases don't match")
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	count, err := c.app.ExportArchive(file, passphrase)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d pastes to %s\n", count, path)
+	return nil
+}
+
+// importArchive logs in and adds the pastes of the archive at path
+func (c *cli) importArchive(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := c.login(); err != nil {
+		return err
+	}
+
+	passphrase, err := c.readSecret("Archive passphrase: ")
+	if err != nil {
+		return err
+	}
+
+	result, err := c.app.ImportArchive(file, passphrase)
+	if result != nil {
+		fmt.Fprintln(os.Stderr, importSummary(result))
+	}
+	return err
+}
+
+// newPaste creates a paste from stdin and prints its ID, and its share
+// link when it is public
+func (c *cli) newPaste(args []string) error {
+	flags := flag.NewFlagSet("new", flag.ContinueOnError)
+	title := flags.String("title", "", "title of the paste (default: the time it was created)")
+	public := flags.Bool("public", false, "make the paste public and print a share link")
+	language := flags.String("language", "", "language to highlight the paste in (default: detect it)")
+	format := flags.String("format", "", "plain, code or markdown (default: suit the language)")
+	tags := flags.String("tags", "", "comma separated tags")
+	redact := flags.Bool("redact", false, "redact likely secrets in a public paste without asking")
+	allowSecrets := flags.Bool("allow-secrets", false, "publish a public paste without checking it for secrets")
+	noCompress := flags.Bool("no-compress", false, "never compress the paste, for input mixing secrets with text from others")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || (*redact && *allowSecrets) {
+		return errors.New(cliUsage)
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintln(os.Stderr, "Enter the paste, then press Ctrl-D (Ctrl-Z and Enter on Windows):")
+	}
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return errors.New("nothing to paste: stdin was empty")
+	}
+
+	// Stdin is used up, so ask for credentials on the terminal
+	if err := c.promptOnTerminal(); err != nil {
+		return err
+	}
+
+	text := string(content)
+	if *public && !*allowSecrets {
+		if err := c.checkSecrets(title, &text, *redact); err != nil {
+			return err
+		}
+	}
+
+	if err := c.login(); err != nil {
+		return err
+	}
+
+	if *title == "" {
+		*title = "Pasted " + time.Now().Format("2006-01-02 15:04")
+	}
+
+	var tagList []string
+	for _, tag := range strings.Split(*tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tagList = append(tagList, tag)
+		}
+	}
+
+	paste, err := c.app.CreatePaste(*title, text, core.PasteOptions{
+		IsPublic:   *public,
+		Sign:       true,
+		NoCompress: *noCompress,
+		Tags:       tagList,
+		Language:   *language,
+		Format:     *format,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.out, paste.ID)
+	if *public {
+		link, err := c.app.ShareLink(paste.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.out, link)
+	}
+	return nil
+}
+
+// checkSecrets looks for likely secrets in a paste about to be made public
+// and, unless told to redact them, asks what to do about them
+func (c *cli) checkSecrets(title, content *string, redact bool) error {
+	found := describeSecrets(*title, *content)
+	if len(found) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, "This paste looks like it contains secrets, which anyone with the link would be able to read:")
+	for _, line := range found {
+		fmt.Fprintln(os.Stderr, "  "+line)
+	}
+
+	for !redact {
+		answer, err := c.readLine("Redact them (r), publish anyway (p) or cancel (c)? ")
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(answer) {
+		case "r", "redact":
+			redact = true
+		case "p", "publish":
+			return nil
+		case "c", "cancel", "":
+			return errors.New("cancelled: nothing was pasted")
+		}
+	}
+
+	*title, *content = redactSecrets(*title, *content)
+	fmt.Fprintf(os.Stderr, "Redacted %d likely secrets\n", len(found))
+	return nil
+}
+
+// getPaste writes the content of a paste to stdout. Share links are opened
+// without logging in.
+func (c *cli) getPaste(ref string) error {
+	var view *core.PasteView
+	var err error
+	if strings.HasPrefix(ref, "pastepal://") {
+		view, err = c.app.OpenShareLink(ref)
+	} else {
+		if err := c.login(); err != nil {
+			return err
+		}
+		view, err = c.app.GetPaste(ref)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(c.out, view.Content)
+	return err
+}
+
+// promptOnTerminal reads answers from the terminal rather than stdin
+func (c *cli) promptOnTerminal() error {
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+
+	tty, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("no terminal to ask for your password on: %v", err)
+	}
+
+	c.input = bufio.NewReader(tty)
+	c.secretFD = int(tty.Fd())
+	return nil
+}
+
+// exportPlaintext logs in and writes the pastes matching the flags to a
+// path unencrypted, once the user has confirmed it
+func (c *cli) exportPlaintext(args []string) error {
+	flags := flag.NewFlagSet("export-plain", flag.ContinueOnError)
+	format := flags.String("format", string(core.PlaintextDirectory), "dir, json or markdown")
+	tag := flags.String("tag", "", "only export pastes with this tag")
+	folder := flags.String("folder", "", "only export pastes in this folder or below it, such as \"Work / Notes\"")
+	since := flags.String("since", "", "only export pastes created on or after this date")
+	until := flags.String("until", "", "only export pastes created on or before this date")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(cliUsage)
+	}
+	path := flags.Arg(0)
+
+	filter := core.PasteFilter{Tag: *tag}
+	var err error
+	if filter.Since, err = parseExportDate(*since, false); err != nil {
+		return err
+	}
+	if filter.Until, err = parseExportDate(*until, true); err != nil {
+		return err
+	}
+
+	if err := c.login(); err != nil {
+		return err
+	}
+
+	if *folder != "" {
+		folders, err := c.app.GetFolders()
+		if err != nil {
+			return err
+		}
+		for _, f := range folders {
+			if strings.EqualFold(core.FolderPath(folders, f.ID), *folder) {
+				filter.FolderID = f.ID
+			}
+		}
+		if filter.FolderID == "" {
+			return fmt.Errorf("no folder %q", *folder)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\nWARNING: this writes your pastes to %s WITHOUT ENCRYPTION.\n", path)
+	fmt.Fprintln(os.Stderr, "Anyone and anything that can read that path can read them, and deleted")
+	fmt.Fprintln(os.Stderr, "files may stay recoverable from disk or backups.")
+	answer, err := c.readLine(fmt.Sprintf("Type %q to continue: ", plaintextConfirmation))
+	if err != nil {
+		return err
+	}
+
+	count, err := c.app.ExportPlaintext(path, core.PlaintextExportOptions{
+		Format:    core.PlaintextFormat(*format),
+		Filter:    filter,
+		Confirmed: answer == plaintextConfirmation,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d pastes unencrypted to %s\n", count, path)
+	return nil
+}
+
+// login asks for the account's credentials, and a two-factor code when the
+// server wants one
+func (c *cli) login() error {
+	saved, _ := c.app.LocalStorage.GetSavedCredentials()
+
+	prompt := "Email: "
+	if saved != "" {
+		prompt = fmt.Sprintf("Email [%s]: ", saved)
+	}
+	email, err := c.readLine(prompt)
+	if err != nil {
+		return err
+	}
+	if email == "" {
+		email = saved
+	}
+	if email == "" {
+		return errors.New("an email is required")
+	}
+
+	password, err := c.readSecret("Master password: ")
+	if err != nil {
+		return err
+	}
+
+	// Leave the remembered email as it is
+	err = c.app.Login(email, password, saved != "" && saved == email)
+	if errors.Is(err, api.ErrTwoFactorRequired) {
+		code, readErr := c.readLine("Two-factor code (or recovery code): ")
+		if readErr != nil {
+			c.app.CancelTwoFactorLogin()
+			return readErr
+		}
+		// Authenticator codes are all digits, recovery codes aren't
+		isRecoveryCode := strings.Trim(code, "0123456789 ") != ""
+		err = c.app.CompleteTwoFactorLogin(code, isRecoveryCode)
+	}
+	return err
+}
+
+// readLine prompts for a line of input
+func (c *cli) readLine(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := c.input.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readSecret prompts for input without echoing it when it comes from a
+// terminal
+func (c *cli) readSecret(prompt string) (string, error) {
+	if !term.IsTerminal(c.secretFD) {
+		return c.readLine(prompt)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(c.secretFD)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// importSummary describes the outcome of an import
+func importSummary(result *core.ImportResult) string {
+	return fmt.Sprintf("Imported %d pastes, skipped %d already in your account and %d expired",
+		result.Imported, result.Duplicates, result.Expired)
+}
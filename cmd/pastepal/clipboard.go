@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// How long a copy button shows that it copied
+const copiedFeedback = 2 * time.Second
+
+// copySecret puts paste content or a share link on the clipboard. It is
+// cleared again after the configured delay unless something else was
+// copied since.
+func (g *GUI) copySecret(text string) {
+	clipboard := g.mainWindow.Clipboard()
+
+	g.copiedSecretMutex.Lock()
+	clipboard.SetContent(text)
+	g.copiedSecret = text
+	g.copiedSecretMutex.Unlock()
+
+	seconds := g.pasteApp.GetConfig().ClipboardClearSeconds
+	if seconds <= 0 {
+		return
+	}
+
+	time.AfterFunc(time.Duration(seconds)*time.Second, func() {
+		g.copiedSecretMutex.Lock()
+		defer g.copiedSecretMutex.Unlock()
+
+		if clipboard.Content() == text {
+			clipboard.SetContent("")
+		}
+		if g.copiedSecret == text {
+			g.copiedSecret = ""
+		}
+	})
+}
+
+// clearCopiedSecret empties the clipboard if it still holds something
+// copySecret put there
+func (g *GUI) clearCopiedSecret() {
+	g.copiedSecretMutex.Lock()
+	defer g.copiedSecretMutex.Unlock()
+
+	if g.copiedSecret == "" {
+		return
+	}
+
+	clipboard := g.mainWindow.Clipboard()
+	if clipboard.Content() == g.copiedSecret {
+		clipboard.SetContent("")
+	}
+	g.copiedSecret = ""
+}
+
+// copyButton creates a button that copies the text returned by get, which
+// may block, and briefly shows that it did
+func (g *GUI) copyButton(label string, get func() (string, error)) *widget.Button {
+	var button *widget.Button
+	button = widget.NewButtonWithIcon(label, theme.ContentCopyIcon(), func() {
+		button.Disable()
+
+		go func() {
+			defer button.Enable()
+
+			text, err := get()
+			if errors.Is(err, errCopyCancelled) {
+				return
+			}
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to copy: %v", err), g.mainWindow)
+				return
+			}
+			g.copySecret(text)
+
+			feedback := "Copied"
+			if seconds := g.pasteApp.GetConfig().ClipboardClearSeconds; seconds > 0 {
+				feedback = fmt.Sprintf("Copied, clearing in %ds", seconds)
+			}
+			button.SetText(feedback)
+			time.AfterFunc(copiedFeedback, func() {
+				button.SetText(label)
+			})
+		}()
+	})
+	return button
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+)
+
+// How long after the last edit the draft being written is saved
+const draftAutosaveDelay = 2 * time.Second
+
+// draftEditor connects the create tab to the draft it is editing
+type draftEditor struct {
+	mutex sync.Mutex
+	// ID of the draft in the form, empty until it is first saved
+	id    string
+	timer *time.Timer
+
+	// Read the form, fill it from a draft, and empty it
+	read  func() *core.Draft
+	load  func(*core.Draft)
+	reset func()
+}
+
+// scheduleDraftSave saves the draft being written once editing pauses
+func (g *GUI) scheduleDraftSave() {
+	editor := g.draftEditor
+	if editor == nil {
+		return
+	}
+
+	editor.mutex.Lock()
+	defer editor.mutex.Unlock()
+
+	if editor.timer != nil {
+		editor.timer.Stop()
+	}
+	editor.timer = time.AfterFunc(draftAutosaveDelay, g.saveDraft)
+}
+
+// saveDraft saves the draft in the create tab now. An empty form has no
+// draft, so its saved draft is deleted instead. Autosave failures are only
+// logged, as the draft is saved again on the next edit.
+func (g *GUI) saveDraft() {
+	editor := g.draftEditor
+	if editor == nil {
+		return
+	}
+
+	editor.mutex.Lock()
+	defer editor.mutex.Unlock()
+
+	if editor.timer != nil {
+		editor.timer.Stop()
+		editor.timer = nil
+	}
+
+	draft := editor.read()
+	if strings.TrimSpace(draft.Title) == "" && strings.TrimSpace(draft.Content) == "" {
+		if editor.id != "" {
+			if err := g.pasteApp.DiscardDraft(editor.id); err != nil {
+				fmt.Printf("[GUI] Failed to delete empty draft: %v\n", err)
+			}
+			editor.id = ""
+		}
+		return
+	}
+
+	draft.ID = editor.id
+	if err := g.pasteApp.SaveDraft(draft); err != nil {
+		fmt.Printf("[GUI] Failed to save draft: %v\n", err)
+		return
+	}
+	editor.id = draft.ID
+}
+
+// discardCurrentDraft deletes the draft in the create tab and empties it
+func (g *GUI) discardCurrentDraft() {
+	editor := g.draftEditor
+	if editor == nil {
+		return
+	}
+
+	editor.mutex.Lock()
+	if editor.timer != nil {
+		editor.timer.Stop()
+		editor.timer = nil
+	}
+	id := editor.id
+	editor.id = ""
+	editor.mutex.Unlock()
+
+	if id != "" {
+		if err := g.pasteApp.DiscardDraft(id); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to discard draft: %v", err), g.mainWindow)
+		}
+	}
+
+	// Emptying the form schedules a save, which finds nothing to keep
+	editor.reset()
+	g.refreshDrafts()
+}
+
+// openDraft saves the draft being written and continues another one in the
+// create tab
+func (g *GUI) openDraft(draft *core.Draft) {
+	editor := g.draftEditor
+	if editor == nil {
+		return
+	}
+
+	g.saveDraft()
+
+	editor.mutex.Lock()
+	editor.id = draft.ID
+	editor.mutex.Unlock()
+
+	editor.load(draft)
+	g.tabs.SelectIndex(createPasteTab)
+}
+
+// restoreDrafts reopens the most recent draft after logging in, so
+// nothing typed before a crash or logout is lost
+func (g *GUI) restoreDrafts() {
+	drafts, err := g.pasteApp.GetDrafts()
+	if err != nil {
+		fmt.Printf("[GUI] Failed to load drafts: %v\n", err)
+		return
+	}
+	g.refreshDrafts()
+	if len(drafts) == 0 {
+		return
+	}
+
+	g.openDraft(drafts[0])
+
+	message := fmt.Sprintf("Restored your draft from %s.", drafts[0].SavedAt.Format("2006-01-02 15:04"))
+	if len(drafts) > 1 {
+		message += fmt.Sprintf("\n%d more drafts are on the Drafts tab.", len(drafts)-1)
+	}
+	dialog.ShowInformation("Draft Restored", message, g.mainWindow)
+}
+
+// draftTitle names a draft in lists
+func draftTitle(draft *core.Draft) string {
+	if title := strings.TrimSpace(draft.Title); title != "" {
+		return title
+	}
+	return "Untitled draft"
+}
+
+// createDraftsTab creates the tab listing unfinished pastes
+func (g *GUI) createDraftsTab() fyne.CanvasObject {
+	heading := widget.NewLabelWithStyle(
+		"Drafts",
+		fyne.TextAlignLeading,
+		fyne.TextStyle{Bold: true},
+	)
+
+	var drafts []*core.Draft
+
+	list := widget.NewList(
+		func() int { return len(drafts) },
+		func() fyne.CanvasObject {
+			titleLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			savedLabel := widget.NewLabelWithStyle("", fyne.TextAlignTrailing, fyne.TextStyle{})
+			discardButton := widget.NewButtonWithIcon("Discard Draft", theme.DeleteIcon(), nil)
+
+			return container.NewBorder(
+				nil, nil, nil, container.NewHBox(savedLabel, discardButton),
+				titleLabel,
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(drafts) {
+				return
+			}
+
+			draft := drafts[id]
+			border := obj.(*fyne.Container)
+			border.Objects[0].(*widget.Label).SetText(draftTitle(draft))
+
+			trailing := border.Objects[1].(*fyne.Container)
+			trailing.Objects[0].(*widget.Label).SetText(draft.SavedAt.Format("2006-01-02 15:04"))
+			trailing.Objects[1].(*widget.Button).OnTapped = func() {
+				g.confirmDiscardDraft(draft)
+			}
+		},
+	)
+
+	list.OnSelected = func(id widget.ListItemID) {
+		if id >= 0 && id < len(drafts) {
+			g.openDraft(drafts[id])
+		}
+		list.UnselectAll()
+	}
+
+	statusLabel := widget.NewLabelWithStyle(
+		"",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Italic: true},
+	)
+
+	g.refreshDrafts = func() {
+		loaded, err := g.pasteApp.GetDrafts()
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed to load drafts: %v", err))
+			statusLabel.Show()
+			return
+		}
+
+		drafts = loaded
+		list.Refresh()
+
+		if len(drafts) == 0 {
+			statusLabel.SetText("No drafts. Unfinished pastes are saved here as you type.")
+			statusLabel.Show()
+			return
+		}
+		statusLabel.Hide()
+	}
+
+	infoLabel := widget.NewLabel("Drafts are encrypted and kept on this device only. Select one to continue it.")
+
+	header := container.NewBorder(
+		nil, nil, heading, nil,
+		widget.NewSeparator(),
+	)
+
+	return container.NewBorder(
+		container.NewVBox(header, infoLabel),
+		nil, nil, nil,
+		container.NewStack(list, container.NewCenter(statusLabel)),
+	)
+}
+
+// confirmDiscardDraft deletes a draft once the user confirms it
+func (g *GUI) confirmDiscardDraft(draft *core.Draft) {
+	dialog.ShowConfirm(
+		"Discard Draft",
+		fmt.Sprintf("Discard %q? It can't be recovered.", draftTitle(draft)),
+		func(confirm bool) {
+			if !confirm {
+				return
+			}
+
+			// The draft open in the create tab goes with it
+			if editor := g.draftEditor; editor != nil {
+				editor.mutex.Lock()
+				open := editor.id == draft.ID
+				editor.mutex.Unlock()
+				if open {
+					g.discardCurrentDraft()
+					return
+				}
+			}
+
+			if err := g.pasteApp.DiscardDraft(draft.ID); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to discard draft: %v", err), g.mainWindow)
+			}
+			g.refreshDrafts()
+		},
+		g.mainWindow,
+	)
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+)
+
+// allTagsLabel is the tag filter option that shows every paste
+const allTagsLabel = "All tags"
+
+// folderPicker is a select listing the user's folders by their path
+type folderPicker struct {
+	Select    *widget.Select
+	noneLabel string
+	ids       []string // Folder ID of each option, "" for noneLabel
+}
+
+// newFolderPicker creates a folder picker whose first option, noneLabel,
+// stands for no folder
+func newFolderPicker(noneLabel string, folders []*core.Folder) *folderPicker {
+	p := &folderPicker{
+		Select:    widget.NewSelect(nil, nil),
+		noneLabel: noneLabel,
+	}
+	p.update(folders)
+	p.Select.SetSelectedIndex(0)
+	return p
+}
+
+// update replaces the options, keeping the selected folder where it still
+// exists
+func (p *folderPicker) update(folders []*core.Folder) {
+	selected := p.FolderID()
+
+	type option struct{ id, path string }
+	options := make([]option, 0, len(folders))
+	for _, folder := range folders {
+		options = append(options, option{folder.ID, core.FolderPath(folders, folder.ID)})
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return strings.ToLower(options[i].path) < strings.ToLower(options[j].path)
+	})
+
+	p.ids = []string{""}
+	labels := []string{p.noneLabel}
+	for _, opt := range options {
+		p.ids = append(p.ids, opt.id)
+		labels = append(labels, opt.path)
+	}
+
+	// Changing the options must not look like the user picking a folder
+	onChanged := p.Select.OnChanged
+	p.Select.OnChanged = nil
+	p.Select.Options = labels
+	p.SetFolderID(selected)
+	p.Select.OnChanged = onChanged
+	p.Select.Refresh()
+}
+
+// FolderID returns the ID of the selected folder, or "" for none
+func (p *folderPicker) FolderID() string {
+	i := p.Select.SelectedIndex()
+	if i < 0 || i >= len(p.ids) {
+		return ""
+	}
+	return p.ids[i]
+}
+
+// SetFolderID selects a folder, or the none option when it isn't listed
+func (p *folderPicker) SetFolderID(folderID string) {
+	for i, id := range p.ids {
+		if id == folderID {
+			p.Select.SetSelectedIndex(i)
+			return
+		}
+	}
+	p.Select.SetSelectedIndex(0)
+}
+
+// trackFolderPicker keeps a picker's options in step with the folders
+func (g *GUI) trackFolderPicker(p *folderPicker) *folderPicker {
+	g.folderPickers = append(g.folderPickers, p)
+	return p
+}
+
+// loadFolders fetches the user's folders and updates every tracked picker.
+// It blocks, so call it from a goroutine.
+func (g *GUI) loadFolders() {
+	folders, err := g.pasteApp.GetFolders()
+	if err != nil {
+		fmt.Printf("[GUI] Failed to load folders: %v\n", err)
+		return
+	}
+
+	g.folders = folders
+	for _, p := range g.folderPickers {
+		p.update(folders)
+	}
+}
+
+// createPasteFilterBar creates the folder and tag filters of the My Pastes
+// tab
+func (g *GUI) createPasteFilterBar(list *widget.List) fyne.CanvasObject {
+	applyFilter := func() {
+		go g.showFilteredPastes(list)
+	}
+
+	folderFilter := g.trackFolderPicker(newFolderPicker("All folders", g.folders))
+	folderFilter.Select.OnChanged = func(string) {
+		g.pasteFilter.FolderID = folderFilter.FolderID()
+		applyFilter()
+	}
+
+	g.tagFilter = widget.NewSelect([]string{allTagsLabel}, nil)
+	g.tagFilter.SetSelectedIndex(0)
+	g.tagFilter.OnChanged = func(tag string) {
+		if tag == allTagsLabel {
+			tag = ""
+		}
+		g.pasteFilter.Tag = tag
+		applyFilter()
+	}
+
+	foldersBtn := widget.NewButtonWithIcon("Folders", theme.FolderIcon(), func() {
+		g.showFolderManager(list)
+	})
+
+	return container.NewHBox(
+		widget.NewLabel("Folder:"),
+		folderFilter.Select,
+		widget.NewLabel("Tag:"),
+		g.tagFilter,
+		foldersBtn,
+	)
+}
+
+// updateTagFilter lists the tags of the loaded pastes in the tag filter
+func (g *GUI) updateTagFilter() {
+	if g.tagFilter == nil {
+		return
+	}
+
+	options := append([]string{allTagsLabel}, g.pasteApp.CollectTags(g.pastes)...)
+
+	// Keep the selected tag if it is still in use
+	onChanged := g.tagFilter.OnChanged
+	g.tagFilter.OnChanged = nil
+	g.tagFilter.Options = options
+	g.tagFilter.SetSelectedIndex(0)
+	for i, tag := range options {
+		if strings.EqualFold(tag, g.pasteFilter.Tag) {
+			g.tagFilter.SetSelectedIndex(i)
+		}
+	}
+	if g.tagFilter.SelectedIndex() == 0 {
+		g.pasteFilter.Tag = ""
+	}
+	g.tagFilter.OnChanged = onChanged
+	g.tagFilter.Refresh()
+}
+
+// showFolderManager lets the user create, rename and move folders
+func (g *GUI) showFolderManager(pastesList *widget.List) {
+	folders := g.folders
+	selected := -1
+
+	list := widget.NewList(
+		func() int { return len(folders) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= 0 && id < len(folders) {
+				obj.(*widget.Label).SetText(core.FolderPath(folders, folders[id].ID))
+			}
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	// reload picks up the change everywhere folders are shown
+	reload := func() {
+		g.loadFolders()
+		folders = g.folders
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+		g.showFilteredPastes(pastesList)
+	}
+
+	selectedFolder := func() *core.Folder {
+		if selected < 0 || selected >= len(folders) {
+			dialog.ShowError(fmt.Errorf("select a folder first"), g.mainWindow)
+			return nil
+		}
+		return folders[selected]
+	}
+
+	newBtn := widget.NewButtonWithIcon("New", theme.FolderNewIcon(), func() {
+		nameEntry := widget.NewEntry()
+		parentPicker := newFolderPicker("Top level", folders)
+		if selected >= 0 && selected < len(folders) {
+			parentPicker.SetFolderID(folders[selected].ID)
+		}
+
+		items := []*widget.FormItem{
+			widget.NewFormItem("Name", nameEntry),
+			widget.NewFormItem("Inside", parentPicker.Select),
+		}
+		dialog.ShowForm("New Folder", "Create", "Cancel", items, func(submitted bool) {
+			if !submitted {
+				return
+			}
+			go func() {
+				if _, err := g.pasteApp.CreateFolder(nameEntry.Text, parentPicker.FolderID()); err != nil {
+					dialog.ShowError(fmt.Errorf("failed to create folder: %v", err), g.mainWindow)
+					return
+				}
+				reload()
+			}()
+		}, g.mainWindow)
+	})
+
+	renameBtn := widget.NewButtonWithIcon("Rename", theme.DocumentCreateIcon(), func() {
+		folder := selectedFolder()
+		if folder == nil {
+			return
+		}
+
+		nameEntry := widget.NewEntry()
+		nameEntry.SetText(folder.Name)
+
+		items := []*widget.FormItem{widget.NewFormItem("Name", nameEntry)}
+		dialog.ShowForm("Rename Folder", "Rename", "Cancel", items, func(submitted bool) {
+			if !submitted {
+				return
+			}
+			go func() {
+				if err := g.pasteApp.RenameFolder(folder.ID, nameEntry.Text); err != nil {
+					dialog.ShowError(fmt.Errorf("failed to rename folder: %v", err), g.mainWindow)
+					return
+				}
+				reload()
+			}()
+		}, g.mainWindow)
+	})
+
+	moveBtn := widget.NewButtonWithIcon("Move", theme.MoveUpIcon(), func() {
+		folder := selectedFolder()
+		if folder == nil {
+			return
+		}
+
+		parentPicker := newFolderPicker("Top level", folders)
+		parentPicker.SetFolderID(folder.ParentID)
+
+		items := []*widget.FormItem{widget.NewFormItem("Move into", parentPicker.Select)}
+		dialog.ShowForm("Move Folder", "Move", "Cancel", items, func(submitted bool) {
+			if !submitted {
+				return
+			}
+			go func() {
+				if err := g.pasteApp.MoveFolder(folder.ID, parentPicker.FolderID()); err != nil {
+					dialog.ShowError(fmt.Errorf("failed to move folder: %v", err), g.mainWindow)
+					return
+				}
+				reload()
+			}()
+		}, g.mainWindow)
+	})
+
+	listScroll := container.NewScroll(list)
+	listScroll.SetMinSize(fyne.NewSize(360, 240))
+
+	content := container.NewBorder(
+		nil,
+		container.NewHBox(newBtn, renameBtn, moveBtn),
+		nil, nil,
+		listScroll,
+	)
+
+	dialog.ShowCustom("Folders", "Close", content, g.mainWindow)
+}
+
+// showOrganizeDialog lets the user file a paste in a folder and tag it
+func (g *GUI) showOrganizeDialog(pasteID string) {
+	go func() {
+		organization, err := g.pasteApp.GetPasteOrganization(pasteID)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to load paste: %v", err), g.mainWindow)
+			return
+		}
+
+		folderPicker := newFolderPicker("No folder", g.folders)
+		folderPicker.SetFolderID(organization.FolderID)
+
+		tagsEntry := widget.NewEntry()
+		tagsEntry.SetPlaceHolder("Comma separated, e.g. work, nginx")
+		tagsEntry.SetText(strings.Join(organization.Tags, ", "))
+
+		items := []*widget.FormItem{
+			widget.NewFormItem("Folder", folderPicker.Select),
+			widget.NewFormItem("Tags", tagsEntry),
+		}
+
+		dialog.ShowForm("Organize Paste", "Save", "Cancel", items, func(submitted bool) {
+			if !submitted {
+				return
+			}
+
+			go func() {
+				if folderID := folderPicker.FolderID(); folderID != organization.FolderID {
+					if err := g.pasteApp.MovePaste(pasteID, folderID); err != nil {
+						dialog.ShowError(fmt.Errorf("failed to move paste: %v", err), g.mainWindow)
+						return
+					}
+				}
+
+				if err := g.pasteApp.SetPasteTags(pasteID, splitTags(tagsEntry.Text)); err != nil {
+					dialog.ShowError(fmt.Errorf("failed to save tags: %v", err), g.mainWindow)
+				}
+			}()
+		}, g.mainWindow)
+	}()
+}
+
+// splitTags splits comma separated tags
+func splitTags(text string) []string {
+	return strings.Split(text, ",")
+}
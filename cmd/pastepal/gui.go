@@ -1,17 +1,22 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/JacobRWebb/PastePal-OS/internal/api"
+	"github.com/JacobRWebb/PastePal-OS/internal/config"
 	"github.com/JacobRWebb/PastePal-OS/internal/core"
 	"github.com/JacobRWebb/PastePal-OS/internal/models"
 )
@@ -24,22 +29,87 @@ type GUI struct {
 
 	// Current UI state
 	currentContainer fyne.CanvasObject
+
+	// My Pastes tab: pastes as last loaded, and how they are filtered
+	pastes      []*models.Paste
+	pasteFilter core.PasteFilter
+	tagFilter   *widget.Select
+
+	// Folders as last loaded, and the pickers that list them
+	folders       []*core.Folder
+	folderPickers []*folderPicker
+
+	// Paste content or link last put on the clipboard, to clear it again.
+	// Copies and their clearing run on different goroutines.
+	copiedSecret      string
+	copiedSecretMutex sync.Mutex
+
+	// Dashboard tabs, and the create tab's action filling it from the
+	// clipboard
+	tabs              *container.AppTabs
+	fillFromClipboard func()
+
+	// The draft being written in the create tab, and the drafts tab's
+	// reload
+	draftEditor   *draftEditor
+	refreshDrafts func()
+
+	// Reloads of the My Pastes and Trash tabs
+	reloadPastes func()
+	refreshTrash func()
+
+	// Templates as last loaded, and the create tab's picker of them
+	templates      []*core.Template
+	templateSelect *widget.Select
+
+	// The system tray, when PastePal lives there
+	tray desktop.App
 }
 
+// Index of the create tab on the dashboard
+const createPasteTab = 1
+
 // NewGUI creates a new GUI instance
 func NewGUI(pasteApp *core.PastePalApp) *GUI {
 	// Use app.NewWithID instead of app.New to provide a unique ID for preferences
 	fyneApp := app.NewWithID("com.pastepal.app")
-	fyneApp.Settings().SetTheme(theme.DarkTheme())
+	fyneApp.Settings().SetTheme(themeFor(pasteApp.GetConfig().Theme))
 
 	mainWindow := fyneApp.NewWindow("PastePal - Zero Knowledge Security")
 	mainWindow.Resize(fyne.NewSize(800, 600))
 
-	return &GUI{
+	g := &GUI{
 		app:        fyneApp,
 		mainWindow: mainWindow,
 		pasteApp:   pasteApp,
 	}
+
+	// React to config file edits without restarting
+	pasteApp.SubscribeConfig(g.onConfigChanged)
+
+	// Hide the vault whenever it locks, and keep it open while in use
+	pasteApp.SetLockHandler(g.showLockScreen)
+	g.trackActivity()
+
+	// Keep what was being typed when the app is closed
+	mainWindow.SetOnClosed(g.saveDraft)
+
+	return g
+}
+
+// onConfigChanged applies live config changes that affect the GUI
+func (g *GUI) onConfigChanged(oldConfig, newConfig *config.Config) {
+	if newConfig.Theme != oldConfig.Theme {
+		g.app.Settings().SetTheme(themeFor(newConfig.Theme))
+	}
+}
+
+// themeFor maps a configured theme name to a Fyne theme
+func themeFor(name string) fyne.Theme {
+	if name == config.ThemeLight {
+		return theme.LightTheme()
+	}
+	return theme.DarkTheme()
 }
 
 // Run starts the GUI application
@@ -51,11 +121,19 @@ func (g *GUI) Run() {
 		g.showLoginScreen()
 	}
 
+	g.setupTray()
 	g.mainWindow.ShowAndRun()
 }
 
 // showLoginScreen displays the login screen
 func (g *GUI) showLoginScreen() {
+	// A locked session is resumed with the PIN or master password instead
+	if g.pasteApp.IsLoggedIn && g.pasteApp.IsLocked() {
+		g.showLockScreen()
+		return
+	}
+	g.updateTrayMenu()
+
 	// Create input fields
 	emailEntry := widget.NewEntry()
 	emailEntry.SetPlaceHolder("Email")
@@ -94,6 +172,19 @@ func (g *GUI) showLoginScreen() {
 			
 			// Update UI in the main thread
 			g.mainWindow.Canvas().Refresh(g.currentContainer)
+			if errors.Is(err, api.ErrPinMismatch) {
+				// Never let a pin failure read like a typo in the password
+				statusLabel.SetText("Login blocked: the server's certificate does not match the pinned key")
+				loginButton.Enable()
+				dialog.ShowError(err, g.mainWindow)
+				return
+			}
+			if errors.Is(err, api.ErrTwoFactorRequired) {
+				statusLabel.SetText("Enter your two-factor code to continue")
+				loginButton.Enable()
+				g.showTwoFactorLoginPrompt(statusLabel)
+				return
+			}
 			if err != nil {
 				statusLabel.SetText(fmt.Sprintf("Login failed: %v", err))
 				loginButton.Enable()
@@ -109,9 +200,14 @@ func (g *GUI) showLoginScreen() {
 		g.showRegisterScreen()
 	})
 
+	forgotPasswordBtn := widget.NewButton("Forgot Password?", func() {
+		g.showRecoveryScreen()
+	})
+	forgotPasswordBtn.Importance = widget.LowImportance
+
 	// Try to load saved credentials
 	go func() {
-		email, _, err := g.pasteApp.LocalStorage.GetSavedCredentials()
+		email, err := g.pasteApp.LocalStorage.GetSavedCredentials()
 		if err == nil && email != "" {
 			emailEntry.SetText(email)
 			rememberMeCheck.SetChecked(true)
@@ -136,10 +232,11 @@ func (g *GUI) showLoginScreen() {
 		container.NewCenter(widget.NewLabelWithStyle("Secure Zero-Knowledge Paste Sharing", fyne.TextAlignCenter, fyne.TextStyle{Italic: true})),
 		form,
 		container.NewHBox(layout.NewSpacer(), loginButton, registerBtn, layout.NewSpacer()),
+		container.NewCenter(forgotPasswordBtn),
 		statusLabel,
 	)
 
-	// Try auto-login if credentials are saved
+	// Skip the login form if a session is already active
 	go func() {
 		if g.pasteApp.AutoLogin() {
 			// Successfully auto-logged in, show dashboard
@@ -169,6 +266,10 @@ func (g *GUI) showRegisterScreen() {
 	confirmPasswordEntry := widget.NewPasswordEntry()
 	confirmPasswordEntry.SetPlaceHolder("Confirm Password")
 
+	// Recovery is opt-out: without it a forgotten password loses every paste
+	recoveryKeyCheck := widget.NewCheck("Create a recovery key in case I forget my password", nil)
+	recoveryKeyCheck.SetChecked(true)
+
 	// Status label for showing registration progress
 	statusLabel := widget.NewLabelWithStyle(
 		"",
@@ -200,7 +301,7 @@ func (g *GUI) showRegisterScreen() {
 		registerButton.Disable()
 
 		go func() {
-			err := g.pasteApp.Register(email, password)
+			recoveryMnemonic, err := g.pasteApp.Register(email, password, recoveryKeyCheck.Checked)
 			// Update UI in a goroutine-safe way
 			g.mainWindow.Canvas().Refresh(g.currentContainer)
 			if err != nil {
@@ -211,8 +312,12 @@ func (g *GUI) showRegisterScreen() {
 
 			statusLabel.Hide()
 			registerButton.Enable()
-			dialog.ShowInformation("Registration Successful", "Your account has been created. You can now log in.", g.mainWindow)
 			g.showLoginScreen()
+			if recoveryMnemonic != "" {
+				g.showRecoveryKey(recoveryMnemonic, "Your account has been created. You can now log in.")
+			} else {
+				dialog.ShowInformation("Registration Successful", "Your account has been created. You can now log in.", g.mainWindow)
+			}
 		}()
 	})
 
@@ -227,6 +332,7 @@ func (g *GUI) showRegisterScreen() {
 		container.NewPadded(emailEntry),
 		container.NewPadded(passwordEntry),
 		container.NewPadded(confirmPasswordEntry),
+		container.NewPadded(recoveryKeyCheck),
 		statusLabel,
 		container.NewPadded(registerButton),
 		container.NewHBox(
@@ -254,17 +360,49 @@ func (g *GUI) showRegisterScreen() {
 func (g *GUI) showDashboard() {
 	header := g.createHeader()
 
+	// The tabs are rebuilt, so forget the previous ones
+	g.pastes = nil
+	g.pasteFilter = core.PasteFilter{}
+	g.folderPickers = nil
+
+	draftsTab := container.NewTabItem("Drafts", g.createDraftsTab())
+	trashTab := container.NewTabItem("Trash", g.createTrashTab())
+
 	// Create tabs for different sections with improved styling
 	tabs := container.NewAppTabs(
 		container.NewTabItem("My Pastes", g.createPastesListTab()),
 		container.NewTabItem("Create Paste", g.createNewPasteTab()),
+		draftsTab,
+		container.NewTabItem("Shared With Me", g.createSharedPastesTab()),
+		trashTab,
 		container.NewTabItem("Account", g.createAccountTab()),
 	)
 	tabs.SetTabLocation(container.TabLocationTop)
+	tabs.OnSelected = func(item *container.TabItem) {
+		g.pasteApp.RecordActivity()
+		switch item {
+		case draftsTab:
+			go g.refreshDrafts()
+		case trashTab:
+			g.refreshTrash()
+		}
+	}
+	g.tabs = tabs
+	g.updateTrayMenu()
 
 	content := container.NewBorder(header, nil, nil, nil, tabs)
 	g.currentContainer = content
 	g.mainWindow.SetContent(content)
+
+	// Pick up where the last session stopped typing
+	go g.restoreDrafts()
+
+	// Bring older pastes up to the current encryption format in the background
+	go func() {
+		if _, err := g.pasteApp.UpgradePasteEncryption(); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to upgrade paste encryption: %v", err), g.mainWindow)
+		}
+	}()
 }
 
 // createHeader creates the header with app title and logout button
@@ -283,6 +421,9 @@ func (g *GUI) createHeader() fyne.CanvasObject {
 			"Are you sure you want to log out?",
 			func(confirm bool) {
 				if confirm {
+					g.saveDraft()
+					g.draftEditor = nil
+					g.clearCopiedSecret()
 					g.pasteApp.Logout()
 					g.showLoginScreen()
 				}
@@ -291,6 +432,10 @@ func (g *GUI) createHeader() fyne.CanvasObject {
 		)
 	})
 
+	lockBtn := widget.NewButtonWithIcon("Lock", theme.VisibilityOffIcon(), func() {
+		go g.lockVault()
+	})
+
 	// Create a more professional header with subtle separator
 	header := container.NewBorder(
 		nil,
@@ -299,7 +444,7 @@ func (g *GUI) createHeader() fyne.CanvasObject {
 			widget.NewSeparator(),
 		),
 		logoutBtn,
-		nil,
+		lockBtn,
 		titleStyled,
 	)
 
@@ -337,9 +482,10 @@ func (g *GUI) createPastesListTab() fyne.CanvasObject {
 		},
 	)
 
-	refreshBtn.OnTapped = func() {
+	g.reloadPastes = func() {
 		g.refreshPastesList(list)
 	}
+	refreshBtn.OnTapped = g.reloadPastes
 
 	// Initial load of pastes
 	g.refreshPastesList(list)
@@ -358,11 +504,15 @@ func (g *GUI) createPastesListTab() fyne.CanvasObject {
 		widget.NewSeparator(),
 	)
 
+	// Search results take the place of the list while searching
+	pastesView := container.NewStack(list, noContentLabel)
+	searchBar, resultsView := g.createSearchBar(pastesView)
+
 	// Create a more professional container with proper spacing
 	container := container.NewBorder(
-		header,
+		container.NewVBox(header, searchBar, g.createPasteFilterBar(list)),
 		nil, nil, nil,
-		container.NewStack(list, noContentLabel),
+		container.NewStack(pastesView, resultsView),
 	)
 
 	return container
@@ -393,45 +543,60 @@ func (g *GUI) refreshPastesList(list *widget.List) {
 			return
 		}
 
-		// Store pastes for access in the list
-		pastesData := pastes
+		g.pastes = pastes
+		g.loadFolders()
+		g.updateTagFilter()
 
-		// Update list data
-		list.Length = func() int {
-			return len(pastesData)
-		}
+		g.showFilteredPastes(list)
+		g.updateTrayMenu()
 
-		list.UpdateItem = func(id widget.ListItemID, obj fyne.CanvasObject) {
-			if id < 0 || id >= len(pastesData) {
-				return
-			}
+		// Restore the original content
+		g.mainWindow.SetContent(g.currentContainer)
+	}()
+}
 
-			paste := pastesData[id]
-			border := obj.(*fyne.Container)
-			
-			// The title is in the content part of the border layout
-			titleLabel := border.Objects[0].(*widget.Label)
-			titleLabel.SetText(paste.Title)
-			
-			// The date is in the right part of the border layout
-			dateLabel := border.Objects[1].(*widget.Label)
-			dateLabel.SetText(paste.CreatedAt.Format(time.DateOnly))
-		}
+// showFilteredPastes fills the list with the loaded pastes that match the
+// current filter
+func (g *GUI) showFilteredPastes(list *widget.List) {
+	// Store pastes for access in the list
+	pastesData, err := g.pasteApp.FilterPastes(g.pastes, g.folders, g.pasteFilter)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to filter pastes: %v", err), g.mainWindow)
+		pastesData = g.pastes
+	}
 
-		// Set up tap handler
-		list.OnSelected = func(id widget.ListItemID) {
-			if id >= 0 && id < len(pastesData) {
-				g.showPasteDetails(pastesData[id])
-			}
-			list.UnselectAll()
+	// Update list data
+	list.Length = func() int {
+		return len(pastesData)
+	}
+
+	list.UpdateItem = func(id widget.ListItemID, obj fyne.CanvasObject) {
+		if id < 0 || id >= len(pastesData) {
+			return
 		}
 
-		// Refresh the list
-		list.Refresh()
+		paste := pastesData[id]
+		border := obj.(*fyne.Container)
 		
-		// Restore the original content
-		g.mainWindow.SetContent(g.currentContainer)
-	}()
+		// The title is in the content part of the border layout
+		titleLabel := border.Objects[0].(*widget.Label)
+		titleLabel.SetText(paste.Title)
+		
+		// The date is in the right part of the border layout
+		dateLabel := border.Objects[1].(*widget.Label)
+		dateLabel.SetText(paste.CreatedAt.Format(time.DateOnly))
+	}
+
+	// Set up tap handler
+	list.OnSelected = func(id widget.ListItemID) {
+		if id >= 0 && id < len(pastesData) {
+			g.showPasteDetails(pastesData[id])
+		}
+		list.UnselectAll()
+	}
+
+	// Refresh the list
+	list.Refresh()
 }
 
 // showPasteDetails shows the details of a selected paste
@@ -440,7 +605,7 @@ func (g *GUI) showPasteDetails(paste *models.Paste) {
 	progress.Show()
 
 	go func() {
-		title, content, err := g.pasteApp.GetPaste(paste.ID)
+		view, err := g.pasteApp.GetPaste(paste.ID)
 		
 		// Update UI in a goroutine-safe way
 		g.mainWindow.Canvas().Refresh(g.currentContainer)
@@ -452,14 +617,57 @@ func (g *GUI) showPasteDetails(paste *models.Paste) {
 
 		progress.Hide()
 
+		// Links only open public pastes
+		shareLinkButton := g.copyButton("Copy Share Link", func() (string, error) {
+			redact, proceed := g.askAboutSecrets("Share", view.Title, view.Content)
+			if !proceed {
+				return "", errCopyCancelled
+			}
+			if redact {
+				if _, err := g.pasteApp.RedactPaste(paste.ID); err != nil {
+					return "", err
+				}
+				// Copying again shouldn't ask about what is already gone
+				view.Title, view.Content = redactSecrets(view.Title, view.Content)
+				g.reloadPastes()
+			}
+			return g.pasteApp.ShareLink(paste.ID)
+		})
+		if !paste.IsPublic {
+			shareLinkButton.Hide()
+		}
+
+		var details dialog.Dialog
+		trashButton := widget.NewButtonWithIcon("Move to Trash", theme.DeleteIcon(), func() {
+			details.Hide()
+			g.trashPaste(paste.ID, view.Title)
+		})
+		trashButton.Importance = widget.DangerImportance
+
 		contentView := container.NewVBox(
-			widget.NewLabelWithStyle(title, fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			widget.NewLabelWithStyle(view.Title, fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
 			widget.NewLabel(fmt.Sprintf("Created: %s", paste.CreatedAt.Format(time.RFC822))),
+			authorLabel(view.Author),
+			languageLabel(view.Language),
 			widget.NewSeparator(),
-			container.NewScroll(widget.NewLabel(content)),
+			pasteBodyView(view),
+			container.NewHBox(
+				g.copyButton("Copy Content", func() (string, error) {
+					return view.Content, nil
+				}),
+				shareLinkButton,
+				widget.NewButtonWithIcon("Share", theme.MailForwardIcon(), func() {
+					g.showShareDialog(paste.ID, view)
+				}),
+				widget.NewButtonWithIcon("Organize", theme.FolderIcon(), func() {
+					g.showOrganizeDialog(paste.ID)
+				}),
+				trashButton,
+			),
 		)
 
-		dialog.ShowCustom("Paste Details", "Close", contentView, g.mainWindow)
+		details = dialog.NewCustom("Paste Details", "Close", contentView, g.mainWindow)
+		details.Show()
 	}()
 }
 
@@ -478,6 +686,32 @@ func (g *GUI) createNewPasteTab() fyne.CanvasObject {
 
 	contentEntry := widget.NewMultiLineEntry()
 	contentEntry.SetPlaceHolder("Enter paste content here...")
+
+	// Typing into a focused entry doesn't reach the canvas, so count it
+	// here, and save what was typed once the user pauses
+	titleEntry.OnChanged = func(string) {
+		g.pasteApp.RecordActivity()
+		g.scheduleDraftSave()
+	}
+	contentEntry.OnChanged = func(string) {
+		g.pasteApp.RecordActivity()
+		g.scheduleDraftSave()
+	}
+
+	// Start a paste from whatever was copied last
+	g.fillFromClipboard = func() {
+		content := g.mainWindow.Clipboard().Content()
+		if strings.TrimSpace(content) == "" {
+			dialog.ShowInformation("From Clipboard", "The clipboard doesn't hold any text.", g.mainWindow)
+			return
+		}
+
+		contentEntry.SetText(content)
+		if strings.TrimSpace(titleEntry.Text) == "" {
+			titleEntry.SetText("Clipboard " + time.Now().Format("2006-01-02 15:04"))
+		}
+	}
+	fromClipboardButton := widget.NewButtonWithIcon("From Clipboard", theme.ContentPasteIcon(), g.fillFromClipboard)
 	// Set a minimum size for better UX
 	contentScroll := container.NewScroll(contentEntry)
 	contentScroll.SetMinSize(fyne.NewSize(400, 300))
@@ -486,6 +720,30 @@ func (g *GUI) createNewPasteTab() fyne.CanvasObject {
 	isPublicCheck := widget.NewCheck("Make paste public", nil)
 	isPublicCheck.SetChecked(false)
 
+	// Signing lets recipients of shared pastes confirm who wrote them
+	signCheck := widget.NewCheck("Sign paste", nil)
+	signCheck.SetChecked(true)
+
+	// Private pastes without likely secrets are compressed, which the scan
+	// can't always tell is safe
+	noCompressCheck := widget.NewCheck("Never compress (for secrets mixed with text from others)", nil)
+	noCompressCheck.SetChecked(false)
+
+	// Code is highlighted in the language picked here, or the detected one
+	languageSelect := widget.NewSelect(languageOptions(), nil)
+	languageSelect.SetSelected(languageAutoDetect)
+
+	formatSelect := widget.NewSelect(formatOptions(), nil)
+	formatSelect.SetSelected(formatAuto)
+
+	expirySelect := widget.NewSelect(expiryOptions(), nil)
+	setExpiry(expirySelect, 0)
+
+	// Where the paste is filed
+	folderPicker := g.trackFolderPicker(newFolderPicker("No folder", g.folders))
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("Comma separated, e.g. work, nginx")
+
 	// Status label for showing creation progress
 	statusLabel := widget.NewLabelWithStyle(
 		"",
@@ -494,6 +752,58 @@ func (g *GUI) createNewPasteTab() fyne.CanvasObject {
 	)
 	statusLabel.Hide()
 
+	// Settings are saved with the draft too
+	isPublicCheck.OnChanged = func(bool) { g.scheduleDraftSave() }
+	signCheck.OnChanged = func(bool) { g.scheduleDraftSave() }
+	noCompressCheck.OnChanged = func(bool) { g.scheduleDraftSave() }
+	languageSelect.OnChanged = func(string) { g.scheduleDraftSave() }
+	formatSelect.OnChanged = func(string) { g.scheduleDraftSave() }
+	expirySelect.OnChanged = func(string) { g.scheduleDraftSave() }
+	tagsEntry.OnChanged = func(string) { g.scheduleDraftSave() }
+
+	resetForm := func() {
+		titleEntry.SetText("")
+		contentEntry.SetText("")
+		isPublicCheck.SetChecked(false)
+		signCheck.SetChecked(true)
+		noCompressCheck.SetChecked(false)
+		tagsEntry.SetText("")
+		folderPicker.SetFolderID("")
+		languageSelect.SetSelected(languageAutoDetect)
+		formatSelect.SetSelected(formatAuto)
+		setExpiry(expirySelect, 0)
+	}
+
+	g.draftEditor = &draftEditor{
+		read: func() *core.Draft {
+			return &core.Draft{
+				Title:       titleEntry.Text,
+				Content:     contentEntry.Text,
+				Language:    languageFromOption(languageSelect.Selected),
+				Format:      formatFromOption(formatSelect.Selected),
+				Tags:        splitTags(tagsEntry.Text),
+				FolderID:    folderPicker.FolderID(),
+				IsPublic:    isPublicCheck.Checked,
+				Sign:        signCheck.Checked,
+				NoCompress:  noCompressCheck.Checked,
+				ExpiryHours: expiryFromOption(expirySelect.Selected),
+			}
+		},
+		load: func(draft *core.Draft) {
+			titleEntry.SetText(draft.Title)
+			contentEntry.SetText(draft.Content)
+			isPublicCheck.SetChecked(draft.IsPublic)
+			signCheck.SetChecked(draft.Sign)
+			noCompressCheck.SetChecked(draft.NoCompress)
+			tagsEntry.SetText(strings.Join(draft.Tags, ", "))
+			folderPicker.SetFolderID(draft.FolderID)
+			languageSelect.SetSelected(languageOption(draft.Language))
+			formatSelect.SetSelected(formatOption(draft.Format))
+			setExpiry(expirySelect, draft.ExpiryHours)
+		},
+		reset: resetForm,
+	}
+
 	// Create buttons with better styling
 	var createButton *widget.Button
 	createButton = widget.NewButton("Create Paste", func() {
@@ -505,14 +815,43 @@ func (g *GUI) createNewPasteTab() fyne.CanvasObject {
 			return
 		}
 
-		// Show status in the app instead of progress dialog
-		statusLabel.SetText("Creating your paste...")
-		statusLabel.Show()
 		createButton.Disable()
 
+		var expiresAt time.Time
+		if hours := expiryFromOption(expirySelect.Selected); hours > 0 {
+			expiresAt = time.Now().Add(time.Duration(hours) * time.Hour)
+		}
+
 		go func() {
+			// Anyone can read a public paste, so check it for secrets first
+			if isPublicCheck.Checked {
+				redact, proceed := g.askAboutSecrets("Publish", title, content)
+				if !proceed {
+					createButton.Enable()
+					return
+				}
+				if redact {
+					title, content = redactSecrets(title, content)
+					titleEntry.SetText(title)
+					contentEntry.SetText(content)
+				}
+			}
+
+			// Show status in the app instead of progress dialog
+			statusLabel.SetText("Creating your paste...")
+			statusLabel.Show()
+
 			// Call the correct method with all required parameters
-			paste, err := g.pasteApp.CreatePaste(title, content, isPublicCheck.Checked, 0, 0)
+			paste, err := g.pasteApp.CreatePaste(title, content, core.PasteOptions{
+				IsPublic:   isPublicCheck.Checked,
+				ExpiresAt:  expiresAt,
+				Sign:       signCheck.Checked,
+				NoCompress: noCompressCheck.Checked,
+				Tags:       splitTags(tagsEntry.Text),
+				FolderID:   folderPicker.FolderID(),
+				Language:   languageFromOption(languageSelect.Selected),
+				Format:     formatFromOption(formatSelect.Selected),
+			})
 			
 			// Update UI in a goroutine-safe way
 			g.mainWindow.Canvas().Refresh(g.currentContainer)
@@ -526,18 +865,15 @@ func (g *GUI) createNewPasteTab() fyne.CanvasObject {
 			createButton.Enable()
 			dialog.ShowInformation("Success", fmt.Sprintf("Your paste has been created with ID: %s", paste.ID), g.mainWindow)
 			
-			// Clear the form
-			titleEntry.SetText("")
-			contentEntry.SetText("")
-			isPublicCheck.SetChecked(false)
+			// The draft became a paste, so clear it and the form
+			g.discardCurrentDraft()
 		}()
 	})
 	createButton.Importance = widget.HighImportance
 
-	clearButton := widget.NewButton("Clear", func() {
-		titleEntry.SetText("")
-		contentEntry.SetText("")
-		isPublicCheck.SetChecked(false)
+	// Clearing the form throws away its draft
+	clearButton := widget.NewButton("Discard Draft", func() {
+		g.discardCurrentDraft()
 	})
 
 	// Create a more professional form layout with proper spacing
@@ -546,11 +882,27 @@ func (g *GUI) createNewPasteTab() fyne.CanvasObject {
 		widget.NewSeparator(),
 		container.NewPadded(
 			container.NewVBox(
+				g.createTemplateBar(),
 				widget.NewLabelWithStyle("Title", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 				titleEntry,
-				widget.NewLabelWithStyle("Content", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				container.NewBorder(nil, nil,
+					widget.NewLabelWithStyle("Content", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+					fromClipboardButton,
+				),
 				contentScroll,
+				widget.NewLabelWithStyle("Language", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				languageSelect,
+				widget.NewLabelWithStyle("Format", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				formatSelect,
+				widget.NewLabelWithStyle("Folder", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				folderPicker.Select,
+				widget.NewLabelWithStyle("Tags", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				tagsEntry,
+				widget.NewLabelWithStyle("Expires", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				expirySelect,
 				isPublicCheck,
+				signCheck,
+				noCompressCheck,
 				statusLabel,
 				container.NewHBox(
 					layout.NewSpacer(),
@@ -648,6 +1000,18 @@ func (g *GUI) createAccountTab() fyne.CanvasObject {
 		accountInfoCard,
 		widget.NewSeparator(),
 		securityInfoCard,
+		widget.NewSeparator(),
+		g.createTwoFactorCard(),
+		widget.NewSeparator(),
+		g.createRecoveryKeyCard(),
+		widget.NewSeparator(),
+		g.createSharingKeyCard(),
+		widget.NewSeparator(),
+		g.createPINCard(),
+		widget.NewSeparator(),
+		g.createAccountSettingsCard(),
+		widget.NewSeparator(),
+		g.createBackupCard(),
 	)
 
 	return container.NewPadded(form)
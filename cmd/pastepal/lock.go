@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+	"github.com/JacobRWebb/PastePal-OS/internal/storage"
+)
+
+// trackActivity postpones the idle auto-lock while the user types in the
+// window without a focused widget
+func (g *GUI) trackActivity() {
+	canvas := g.mainWindow.Canvas()
+	if desktopCanvas, ok := canvas.(desktop.Canvas); ok {
+		desktopCanvas.SetOnKeyDown(func(*fyne.KeyEvent) {
+			g.pasteApp.RecordActivity()
+		})
+	}
+	canvas.SetOnTypedRune(func(rune) {
+		g.pasteApp.RecordActivity()
+	})
+}
+
+// lockVault saves the draft being written, which needs the keys, and locks
+// the vault
+func (g *GUI) lockVault() {
+	g.saveDraft()
+	g.pasteApp.Lock()
+}
+
+// showLockScreen hides the vault behind an unlock prompt. It is called by
+// the app after the keys have been wiped. The prompt asks for the quick
+// unlock PIN when one is set up, and the master password otherwise.
+func (g *GUI) showLockScreen() {
+	g.clearCopiedSecret()
+
+	// Close dialogs, which may show decrypted pastes
+	overlays := g.mainWindow.Canvas().Overlays()
+	for overlays.Top() != nil {
+		overlays.Remove(overlays.Top())
+	}
+
+	lockedContent := g.currentContainer
+
+	title := widget.NewLabelWithStyle(
+		"PastePal is Locked",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	email := ""
+	if g.pasteApp.CurrentUser != nil {
+		email = g.pasteApp.CurrentUser.Email
+	}
+	emailLabel := widget.NewLabelWithStyle(email, fyne.TextAlignCenter, fyne.TextStyle{})
+
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder("Master Password")
+
+	statusLabel := widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{})
+	statusLabel.Hide()
+
+	usePIN := g.pasteApp.HasPIN()
+	var switchButton *widget.Button
+
+	// usePassword falls back to the master password, for good when the
+	// PIN stops working
+	usePassword := func() {
+		usePIN = false
+		passwordEntry.SetText("")
+		passwordEntry.SetPlaceHolder("Master Password")
+		switchButton.Hide()
+		g.mainWindow.Canvas().Focus(passwordEntry)
+	}
+
+	var unlockButton *widget.Button
+	unlock := func() {
+		secret := strings.TrimSpace(passwordEntry.Text)
+		if secret == "" {
+			return
+		}
+
+		statusLabel.SetText("Unlocking...")
+		statusLabel.Show()
+		unlockButton.Disable()
+
+		withPIN := usePIN
+		go func() {
+			var err error
+			if withPIN {
+				err = g.pasteApp.UnlockWithPIN(secret)
+			} else {
+				err = g.pasteApp.Unlock(secret)
+			}
+			passwordEntry.SetText("")
+			unlockButton.Enable()
+
+			if errors.Is(err, core.ErrPINInvalidated) || errors.Is(err, core.ErrPINExpired) || errors.Is(err, storage.ErrNoPIN) {
+				statusLabel.SetText(err.Error())
+				usePassword()
+				return
+			}
+			if err != nil {
+				statusLabel.SetText(fmt.Sprintf("Unlock failed: %v", err))
+				return
+			}
+
+			g.currentContainer = lockedContent
+			g.mainWindow.SetContent(lockedContent)
+			g.updateTrayMenu()
+		}()
+	}
+
+	unlockButton = widget.NewButton("Unlock", unlock)
+	unlockButton.Importance = widget.HighImportance
+	passwordEntry.OnSubmitted = func(string) { unlock() }
+
+	switchButton = widget.NewButton("Use Master Password", func() {
+		statusLabel.Hide()
+		usePassword()
+	})
+	if usePIN {
+		passwordEntry.SetPlaceHolder("PIN")
+	} else {
+		switchButton.Hide()
+	}
+
+	logoutButton := widget.NewButton("Log Out", func() {
+		dialog.ShowConfirm(
+			"Confirm Logout",
+			"Log out instead of unlocking?",
+			func(confirm bool) {
+				if confirm {
+					g.pasteApp.Logout()
+					g.showLoginScreen()
+				}
+			},
+			g.mainWindow,
+		)
+	})
+
+	lockCard := container.NewVBox(
+		container.NewCenter(container.NewPadded(title)),
+		emailLabel,
+		widget.NewSeparator(),
+		container.NewPadded(passwordEntry),
+		statusLabel,
+		container.NewPadded(unlockButton),
+		container.NewPadded(switchButton),
+		container.NewPadded(logoutButton),
+	)
+
+	lockScreen := container.NewCenter(container.NewPadded(lockCard))
+	g.currentContainer = lockScreen
+	g.mainWindow.SetContent(lockScreen)
+	g.mainWindow.Canvas().Focus(passwordEntry)
+	g.updateTrayMenu()
+}
@@ -8,6 +8,13 @@ import (
 )
 
 func main() {
+	// Subcommands keep stdout for output that may be piped into other
+	// tools, so log messages go to stderr
+	out := os.Stdout
+	if len(os.Args) > 1 {
+		os.Stdout = os.Stderr
+	}
+
 	// Initialize the application
 	configPath := core.GetConfigPath()
 	app, err := core.NewApp(configPath)
@@ -15,6 +22,30 @@ func main() {
 		fmt.Printf("Error initializing application: %v\n", err)
 		os.Exit(1)
 	}
+	defer app.Close()
+
+	// Subcommands run without the GUI
+	if len(os.Args) > 1 {
+		if err := runCommand(app, os.Args[1:], out); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			app.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Apply config file edits while running
+	if err := app.StartConfigWatcher(); err != nil {
+		fmt.Printf("Warning: config changes will require a restart: %v\n", err)
+	}
+
+	// Lock the vault when idle or when the screen locks
+	if err := app.StartAutoLock(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	// Purge deleted pastes past the retention period while logged in
+	app.StartTrashPurge()
 
 	// Create and run the GUI
 	gui := NewGUI(app)
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// markdownView renders markdown content. Images are shown as their address
+// instead, since loading one would tell its server the paste was opened.
+func markdownView(content string) fyne.CanvasObject {
+	rich := widget.NewRichText(markdownSegments(content)...)
+	rich.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewScroll(rich)
+	scroll.SetMinSize(fyne.NewSize(560, 320))
+	return scroll
+}
+
+// markdownSegments parses markdown into rich text segments the way Fyne's
+// own markdown support does, except that images become text. Fyne loads
+// images as soon as a rich text widget holding them is created, so they
+// must never get that far.
+func markdownSegments(content string) []widget.RichTextSegment {
+	source := []byte(content)
+	document := goldmark.New().Parser().Parse(text.NewReader(source))
+	return markdownChildren(source, document, false)
+}
+
+// markdownNode converts one markdown node and its children
+func markdownNode(source []byte, n ast.Node, blockquote bool) []widget.RichTextSegment {
+	switch node := n.(type) {
+	case *ast.Document, *ast.TextBlock:
+		return markdownChildren(source, n, blockquote)
+	case *ast.Blockquote:
+		return markdownChildren(source, n, true)
+	case *ast.Paragraph:
+		children := markdownChildren(source, n, blockquote)
+		if !blockquote {
+			children = append(children, &widget.TextSegment{Style: widget.RichTextStyleParagraph})
+		}
+		return children
+	case *ast.List:
+		return []widget.RichTextSegment{&widget.ListSegment{
+			Items:   markdownChildren(source, n, blockquote),
+			Ordered: node.IsOrdered(),
+		}}
+	case *ast.ListItem:
+		return []widget.RichTextSegment{&widget.ParagraphSegment{Texts: markdownChildren(source, n, blockquote)}}
+	case *ast.Heading:
+		heading := &widget.TextSegment{Style: widget.RichTextStyleParagraph, Text: markdownText(source, n, "")}
+		switch node.Level {
+		case 1:
+			heading.Style = widget.RichTextStyleHeading
+		case 2:
+			heading.Style = widget.RichTextStyleSubHeading
+		default:
+			heading.Style.TextStyle.Bold = true
+		}
+		return []widget.RichTextSegment{heading}
+	case *ast.ThematicBreak:
+		return []widget.RichTextSegment{&widget.SeparatorSegment{}}
+	case *ast.Link:
+		link, _ := url.Parse(string(node.Destination))
+		return []widget.RichTextSegment{&widget.HyperlinkSegment{
+			Alignment: fyne.TextAlignLeading,
+			Text:      markdownText(source, n, " "),
+			URL:       link,
+		}}
+	case *ast.Image:
+		return []widget.RichTextSegment{&widget.TextSegment{
+			Style: widget.RichTextStyleEmphasis,
+			Text:  "[Image not loaded: " + string(node.Destination) + "]",
+		}}
+	case *ast.CodeSpan:
+		return []widget.RichTextSegment{&widget.TextSegment{Style: widget.RichTextStyleCodeInline, Text: markdownText(source, n, " ")}}
+	case *ast.CodeBlock, *ast.FencedCodeBlock:
+		var code strings.Builder
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			code.Write(line.Value(source))
+		}
+		if code.Len() == 0 {
+			return nil
+		}
+		return []widget.RichTextSegment{&widget.TextSegment{
+			Style: widget.RichTextStyleCodeBlock,
+			Text:  strings.TrimSuffix(code.String(), "\n"),
+		}}
+	case *ast.Emphasis:
+		style := widget.RichTextStyleEmphasis
+		if node.Level == 2 {
+			style = widget.RichTextStyleStrong
+		}
+		return []widget.RichTextSegment{&widget.TextSegment{Style: style, Text: markdownText(source, n, " ")}}
+	case *ast.Text:
+		value := string(node.Text(source))
+		if value == "" {
+			// An empty text is a line break after something other than text
+			return []widget.RichTextSegment{&widget.TextSegment{Style: widget.RichTextStyleInline, Text: " "}}
+		}
+		if next := n.NextSibling(); next != nil && next.Type() == ast.TypeInline && !strings.HasSuffix(value, " ") {
+			value += " "
+		}
+		style := widget.RichTextStyleInline
+		if blockquote {
+			style = widget.RichTextStyleBlockquote
+		}
+		return []widget.RichTextSegment{&widget.TextSegment{Style: style, Text: value}}
+	}
+	return nil
+}
+
+// markdownChildren converts the children of a markdown node
+func markdownChildren(source []byte, n ast.Node, blockquote bool) []widget.RichTextSegment {
+	segments := make([]widget.RichTextSegment, 0, n.ChildCount())
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		segments = append(segments, markdownNode(source, child, blockquote)...)
+	}
+	return segments
+}
+
+// markdownText joins the plain text inside a markdown node
+func markdownText(source []byte, n ast.Node, separator string) string {
+	var texts []string
+	ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := child.(*ast.Text); ok && entering {
+			texts = append(texts, string(t.Text(source)))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.Join(texts, separator)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createPINCard creates the quick unlock section of the account tab
+func (g *GUI) createPINCard() fyne.CanvasObject {
+	heading := widget.NewLabelWithStyle(
+		"Quick Unlock PIN",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	infoLabel := widget.NewLabel("A PIN unlocks the vault on this device instead of your master password.\n" +
+		"It stops working when you log out, after too many wrong attempts, or a\n" +
+		"while after it was set up.")
+
+	statusLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{})
+
+	var setupButton, removeButton *widget.Button
+
+	refresh := func() {
+		if g.pasteApp.HasPIN() {
+			statusLabel.SetText("Status: Enabled")
+			setupButton.SetText("Change PIN")
+			removeButton.Show()
+		} else {
+			statusLabel.SetText("Status: Disabled")
+			setupButton.SetText("Set Up PIN")
+			removeButton.Hide()
+		}
+	}
+
+	setupButton = widget.NewButton("Set Up PIN", func() {
+		g.showPINSetup(refresh)
+	})
+	setupButton.Importance = widget.HighImportance
+
+	removeButton = widget.NewButton("Remove PIN", func() {
+		dialog.ShowConfirm(
+			"Remove PIN",
+			"Stop unlocking with a PIN on this device?",
+			func(confirm bool) {
+				if !confirm {
+					return
+				}
+				if err := g.pasteApp.RemovePIN(); err != nil {
+					dialog.ShowError(fmt.Errorf("failed to remove PIN: %v", err), g.mainWindow)
+				}
+				refresh()
+			},
+			g.mainWindow,
+		)
+	})
+
+	refresh()
+
+	return container.NewVBox(
+		heading,
+		widget.NewSeparator(),
+		container.NewPadded(infoLabel),
+		container.NewPadded(statusLabel),
+		container.NewPadded(container.NewHBox(setupButton, removeButton)),
+	)
+}
+
+// showPINSetup asks for a new PIN and enables quick unlock with it
+func (g *GUI) showPINSetup(onDone func()) {
+	pinEntry := widget.NewPasswordEntry()
+	pinEntry.SetPlaceHolder("4 to 12 digits")
+
+	confirmEntry := widget.NewPasswordEntry()
+	confirmEntry.SetPlaceHolder("Repeat the PIN")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("PIN", pinEntry),
+		widget.NewFormItem("Confirm", confirmEntry),
+	}
+
+	dialog.ShowForm("Quick Unlock PIN", "Save", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+
+		pin := strings.TrimSpace(pinEntry.Text)
+		if pin != strings.TrimSpace(confirmEntry.Text) {
+			dialog.ShowError(fmt.Errorf("PINs do not match"), g.mainWindow)
+			return
+		}
+
+		progress := dialog.NewProgress("Quick Unlock PIN", "Saving PIN...", g.mainWindow)
+		progress.Show()
+
+		go func() {
+			err := g.pasteApp.SetupPIN(pin)
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to set up PIN: %v", err), g.mainWindow)
+				return
+			}
+
+			onDone()
+			dialog.ShowInformation("Quick Unlock PIN", "You can now unlock PastePal with your PIN.", g.mainWindow)
+		}()
+	}, g.mainWindow)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showRecoveryKey displays a newly generated recovery key mnemonic once
+func (g *GUI) showRecoveryKey(mnemonic, intro string) {
+	mnemonicEntry := widget.NewMultiLineEntry()
+	mnemonicEntry.SetText(mnemonic)
+	mnemonicEntry.Wrapping = fyne.TextWrapWord
+	mnemonicEntry.Disable()
+
+	content := container.NewVBox(
+		widget.NewLabel(intro),
+		widget.NewLabelWithStyle("Your recovery key", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		mnemonicEntry,
+		widget.NewLabel("Print or write down these words and keep them somewhere safe.\n"+
+			"They are the only way to reset your password without losing your pastes,\n"+
+			"and they will not be shown again."),
+	)
+
+	dialog.ShowCustom("Recovery Key", "I have saved it", content, g.mainWindow)
+}
+
+// showRecoveryScreen displays the forgotten password flow
+func (g *GUI) showRecoveryScreen() {
+	title := widget.NewLabelWithStyle(
+		"Recover Account",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+	titleStyled := container.NewCenter(container.NewPadded(title))
+
+	emailEntry := widget.NewEntry()
+	emailEntry.SetPlaceHolder("Email")
+
+	mnemonicEntry := widget.NewMultiLineEntry()
+	mnemonicEntry.SetPlaceHolder("Recovery key words, separated by spaces")
+	mnemonicEntry.Wrapping = fyne.TextWrapWord
+
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder("New Password")
+
+	confirmPasswordEntry := widget.NewPasswordEntry()
+	confirmPasswordEntry.SetPlaceHolder("Confirm New Password")
+
+	twoFactorEntry := widget.NewEntry()
+	twoFactorEntry.SetPlaceHolder("Two-factor code (if enabled)")
+
+	// Status label for showing recovery progress
+	statusLabel := widget.NewLabelWithStyle(
+		"",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{},
+	)
+	statusLabel.Hide()
+
+	var recoverButton *widget.Button
+	recoverButton = widget.NewButton("Reset Password", func() {
+		email := strings.TrimSpace(emailEntry.Text)
+		mnemonic := strings.TrimSpace(mnemonicEntry.Text)
+		password := strings.TrimSpace(passwordEntry.Text)
+		confirmPassword := strings.TrimSpace(confirmPasswordEntry.Text)
+
+		if email == "" || mnemonic == "" || password == "" {
+			dialog.ShowError(fmt.Errorf("email, recovery key and new password are required"), g.mainWindow)
+			return
+		}
+
+		if password != confirmPassword {
+			dialog.ShowError(fmt.Errorf("passwords do not match"), g.mainWindow)
+			return
+		}
+
+		statusLabel.SetText("Recovering your account...")
+		statusLabel.Show()
+		recoverButton.Disable()
+
+		go func() {
+			err := g.pasteApp.RecoverAccount(email, mnemonic, password, twoFactorEntry.Text)
+
+			// Update UI in a goroutine-safe way
+			g.mainWindow.Canvas().Refresh(g.currentContainer)
+			if err != nil {
+				statusLabel.SetText(fmt.Sprintf("Recovery failed: %v", err))
+				recoverButton.Enable()
+				return
+			}
+
+			statusLabel.Hide()
+			recoverButton.Enable()
+			dialog.ShowInformation("Password Reset", "Your password has been reset. You can now log in with your new password.", g.mainWindow)
+			g.showLoginScreen()
+		}()
+	})
+	recoverButton.Importance = widget.HighImportance
+
+	backButton := widget.NewButton("Back to Login", func() {
+		g.showLoginScreen()
+	})
+
+	recoveryCard := container.NewVBox(
+		widget.NewLabelWithStyle("Reset your password with your recovery key", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		widget.NewSeparator(),
+		container.NewPadded(emailEntry),
+		container.NewPadded(mnemonicEntry),
+		container.NewPadded(passwordEntry),
+		container.NewPadded(confirmPasswordEntry),
+		container.NewPadded(twoFactorEntry),
+		statusLabel,
+		container.NewPadded(recoverButton),
+		container.NewHBox(
+			layout.NewSpacer(),
+			backButton,
+			layout.NewSpacer(),
+		),
+	)
+
+	content := container.NewPadded(
+		container.NewVBox(
+			layout.NewSpacer(),
+			titleStyled,
+			container.NewPadded(recoveryCard),
+			layout.NewSpacer(),
+		),
+	)
+
+	g.currentContainer = content
+	g.mainWindow.SetContent(content)
+}
+
+// createRecoveryKeyCard creates the recovery key section of the account tab
+func (g *GUI) createRecoveryKeyCard() fyne.CanvasObject {
+	heading := widget.NewLabelWithStyle(
+		"Account Recovery",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	statusLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{})
+
+	var createButton *widget.Button
+
+	refresh := func() {
+		if g.pasteApp.CurrentUser != nil && g.pasteApp.CurrentUser.HasRecoveryKey {
+			statusLabel.SetText("Recovery key: Set up")
+			createButton.SetText("Replace Recovery Key")
+		} else {
+			statusLabel.SetText("Recovery key: Not set up. A forgotten password means losing all pastes.")
+			createButton.SetText("Create Recovery Key")
+		}
+	}
+
+	createButton = widget.NewButton("", func() {
+		message := "A new recovery key will be generated."
+		if g.pasteApp.CurrentUser != nil && g.pasteApp.CurrentUser.HasRecoveryKey {
+			message = "A new recovery key will be generated and your old one will stop working."
+		}
+
+		dialog.ShowConfirm("Recovery Key", message+" Continue?", func(confirm bool) {
+			if !confirm {
+				return
+			}
+
+			go func() {
+				mnemonic, err := g.pasteApp.SetupRecoveryKey()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("failed to create recovery key: %v", err), g.mainWindow)
+					return
+				}
+
+				refresh()
+				g.showRecoveryKey(mnemonic, "Your recovery key has been created.")
+			}()
+		}, g.mainWindow)
+	})
+
+	refresh()
+
+	return container.NewVBox(
+		heading,
+		widget.NewSeparator(),
+		container.NewPadded(statusLabel),
+		container.NewPadded(container.NewHBox(createButton)),
+	)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+	"github.com/JacobRWebb/PastePal-OS/internal/search"
+)
+
+// searchModes are the search modes offered, in the order shown
+var searchModes = []search.Mode{search.ModeSubstring, search.ModeWord, search.ModeRegex}
+
+// createSearchBar creates the search box of the My Pastes tab. While a
+// search is active, the results list takes the place of the pastes list.
+func (g *GUI) createSearchBar(pastesList fyne.CanvasObject) (fyne.CanvasObject, fyne.CanvasObject) {
+	var results []search.Result
+
+	resultsList := widget.NewList(
+		func() int { return len(results) },
+		func() fyne.CanvasObject {
+			titleLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			dateLabel := widget.NewLabelWithStyle("", fyne.TextAlignTrailing, fyne.TextStyle{})
+			snippetLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+			snippetLabel.Truncation = fyne.TextTruncateEllipsis
+
+			return container.NewVBox(
+				container.NewBorder(nil, nil, nil, dateLabel, titleLabel),
+				snippetLabel,
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(results) {
+				return
+			}
+
+			result := results[id]
+			item := obj.(*fyne.Container)
+			row := item.Objects[0].(*fyne.Container)
+
+			row.Objects[0].(*widget.Label).SetText(result.Title)
+			row.Objects[1].(*widget.Label).SetText(result.CreatedAt.Format(time.DateOnly))
+			item.Objects[1].(*widget.Label).SetText(result.Snippet)
+		},
+	)
+	resultsList.OnSelected = func(id widget.ListItemID) {
+		if id >= 0 && id < len(results) {
+			g.showPasteDetails(&models.Paste{
+				ID:        results[id].PasteID,
+				CreatedAt: results[id].CreatedAt,
+			})
+		}
+		resultsList.UnselectAll()
+	}
+	resultsList.Hide()
+
+	noResultsLabel := widget.NewLabelWithStyle(
+		"No pastes match your search.",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Italic: true},
+	)
+	noResultsLabel.Hide()
+
+	modeNames := make([]string, len(searchModes))
+	for i, mode := range searchModes {
+		modeNames[i] = mode.String()
+	}
+	modeSelect := widget.NewSelect(modeNames, nil)
+	modeSelect.SetSelectedIndex(0)
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search your pastes...")
+
+	showPastes := func() {
+		results = nil
+		resultsList.Hide()
+		noResultsLabel.Hide()
+		pastesList.Show()
+	}
+
+	runSearch := func() {
+		query := strings.TrimSpace(searchEntry.Text)
+		if query == "" {
+			showPastes()
+			return
+		}
+		mode := searchModes[modeSelect.SelectedIndex()]
+
+		go func() {
+			found, err := g.pasteApp.Search(query, mode)
+			if err != nil {
+				noResultsLabel.SetText(fmt.Sprintf("Search failed: %v", err))
+				found = nil
+			} else {
+				noResultsLabel.SetText("No pastes match your search.")
+			}
+
+			results = found
+			pastesList.Hide()
+			resultsList.Show()
+			resultsList.Refresh()
+			if len(results) == 0 {
+				noResultsLabel.Show()
+			} else {
+				noResultsLabel.Hide()
+			}
+		}()
+	}
+
+	searchEntry.OnSubmitted = func(string) { runSearch() }
+	searchEntry.OnChanged = func(text string) {
+		g.pasteApp.RecordActivity()
+		if strings.TrimSpace(text) == "" {
+			showPastes()
+		}
+	}
+	modeSelect.OnChanged = func(string) { runSearch() }
+
+	clearBtn := widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() {
+		searchEntry.SetText("")
+		showPastes()
+	})
+
+	bar := container.NewBorder(
+		nil, nil,
+		widget.NewIcon(theme.SearchIcon()),
+		container.NewHBox(modeSelect, clearBtn),
+		searchEntry,
+	)
+
+	return bar, container.NewStack(resultsList, noResultsLabel)
+}
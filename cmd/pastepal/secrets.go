@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/secrets"
+)
+
+// errCopyCancelled is returned to a copy button when the user backed out,
+// so nothing is copied and no error is shown
+var errCopyCancelled = errors.New("copy cancelled")
+
+// describeSecrets lists the likely secrets in a title and content, or
+// returns nothing when there are none
+func describeSecrets(title, content string) []string {
+	var lines []string
+	for _, found := range secrets.Describe(title, secrets.Scan(title)) {
+		lines = append(lines, "title "+found)
+	}
+	return append(lines, secrets.Describe(content, secrets.Scan(content))...)
+}
+
+// redactSecrets replaces the likely secrets in a title and content
+func redactSecrets(title, content string) (string, string) {
+	return secrets.Redact(title, secrets.Scan(title)), secrets.Redact(content, secrets.Scan(content))
+}
+
+// askAboutSecrets scans a title and content that are about to be shared and,
+// when it finds likely secrets, asks whether to go ahead. It reports whether
+// to redact them first and whether to go ahead at all. It waits for the
+// answer, so it must not be called from the UI's own callbacks.
+func (g *GUI) askAboutSecrets(action, title, content string) (redact bool, proceed bool) {
+	found := describeSecrets(title, content)
+	if len(found) == 0 {
+		return false, true
+	}
+
+	type answer struct{ redact, proceed bool }
+	answers := make(chan answer, 1)
+
+	message := widget.NewLabel("This looks like it contains secrets, which anyone you share it\n" +
+		"with would be able to read. The check ran on this device only.")
+	list := widget.NewLabel(strings.Join(found, "\n"))
+	list.TextStyle = fyne.TextStyle{Monospace: true}
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(450, 120))
+
+	warning := dialog.NewCustomWithoutButtons("Likely Secrets Found", container.NewBorder(message, nil, nil, nil, scroll), g.mainWindow)
+
+	reply := func(redact, proceed bool) func() {
+		return func() {
+			answers <- answer{redact, proceed}
+			warning.Hide()
+		}
+	}
+	cancelButton := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), reply(false, false))
+	anywayButton := widget.NewButton(fmt.Sprintf("%s Anyway", action), reply(false, true))
+	anywayButton.Importance = widget.DangerImportance
+	redactButton := widget.NewButtonWithIcon("Redact and Continue", theme.VisibilityOffIcon(), reply(true, true))
+	redactButton.Importance = widget.HighImportance
+
+	warning.SetButtons([]fyne.CanvasObject{cancelButton, anywayButton, redactButton})
+	warning.SetOnClosed(func() {
+		// Closing the dialog any other way cancels
+		select {
+		case answers <- answer{}:
+		default:
+		}
+	})
+	warning.Show()
+
+	got := <-answers
+	return got.redact, got.proceed
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+)
+
+// paddingOptions maps the padding choices shown to the account setting
+var paddingOptions = []struct {
+	label string
+	value string
+}{
+	{"PADMÉ (recommended)", core.PaddingPadme},
+	{"Power of two", core.PaddingPowerOfTwo},
+	{"Off", core.PaddingOff},
+}
+
+// createAccountSettingsCard creates the section of the account tab for
+// settings kept with the account
+func (g *GUI) createAccountSettingsCard() fyne.CanvasObject {
+	heading := widget.NewLabelWithStyle(
+		"Account Settings",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	infoLabel := widget.NewLabel("These settings are stored encrypted with your account and apply\n" +
+		"to all of your devices the next time they log in or unlock.")
+
+	labels := make([]string, len(paddingOptions))
+	for i, option := range paddingOptions {
+		labels[i] = option.label
+	}
+
+	settings, err := g.pasteApp.GetAccountSettings()
+	if err != nil {
+		settings = core.DefaultAccountSettings()
+	}
+
+	paddingSelect := widget.NewSelect(labels, nil)
+	for _, option := range paddingOptions {
+		if option.value == settings.Padding {
+			paddingSelect.SetSelected(option.label)
+		}
+	}
+
+	retentionEntry := widget.NewEntry()
+	retentionEntry.SetText(strconv.Itoa(settings.TrashRetentionDays))
+
+	form := widget.NewForm(
+		widget.NewFormItem("Padding", paddingSelect),
+		widget.NewFormItem("Days in Trash", retentionEntry),
+	)
+
+	saveButton := widget.NewButton("Save Settings", nil)
+	saveButton.Importance = widget.HighImportance
+	saveButton.OnTapped = func() {
+		updated := settings
+		for _, option := range paddingOptions {
+			if option.label == paddingSelect.Selected {
+				updated.Padding = option.value
+			}
+		}
+
+		days, err := strconv.Atoi(strings.TrimSpace(retentionEntry.Text))
+		if err != nil || days <= 0 {
+			dialog.ShowError(errors.New("days in trash must be a whole number of at least 1"), g.mainWindow)
+			return
+		}
+		updated.TrashRetentionDays = days
+
+		saveButton.Disable()
+		go func() {
+			defer saveButton.Enable()
+
+			if err := g.pasteApp.SaveAccountSettings(updated); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to save account settings: %v", err), g.mainWindow)
+				return
+			}
+			settings = updated
+			dialog.ShowInformation("Account Settings", "Your settings were saved.", g.mainWindow)
+		}()
+	}
+
+	return container.NewVBox(
+		heading,
+		widget.NewSeparator(),
+		container.NewPadded(infoLabel),
+		container.NewPadded(form),
+		container.NewPadded(container.NewHBox(saveButton)),
+	)
+}
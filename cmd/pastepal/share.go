@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// showShareDialog asks for a recipient and shares a paste with them after
+// their key fingerprint has been confirmed and the paste has been checked
+// for secrets
+func (g *GUI) showShareDialog(pasteID string, view *core.PasteView) {
+	emailEntry := widget.NewEntry()
+	emailEntry.SetPlaceHolder("Recipient email")
+
+	dialog.ShowForm("Share Paste", "Next", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Email", emailEntry)},
+		func(confirmed bool) {
+			email := strings.TrimSpace(emailEntry.Text)
+			if !confirmed || email == "" {
+				return
+			}
+
+			go func() {
+				recipient, fingerprint, err := g.pasteApp.LookupRecipient(email)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("failed to look up recipient: %v", err), g.mainWindow)
+					return
+				}
+
+				g.confirmShare(pasteID, view, recipient, fingerprint)
+			}()
+		}, g.mainWindow)
+}
+
+// confirmShare shows the recipient's key fingerprint before sharing
+func (g *GUI) confirmShare(pasteID string, view *core.PasteView, recipient *models.PublicKeyInfo, fingerprint string) {
+	fingerprintLabel := widget.NewLabelWithStyle(fingerprint, fyne.TextAlignCenter, fyne.TextStyle{Monospace: true})
+	fingerprintLabel.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Share this paste with %s?", recipient.Email)),
+		widget.NewLabel("Check that this fingerprint matches the one shown in their Account tab:"),
+		fingerprintLabel,
+	)
+
+	dialog.ShowCustomConfirm("Confirm Recipient", "Share", "Cancel", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		go func() {
+			redact, proceed := g.askAboutSecrets("Share", view.Title, view.Content)
+			if !proceed {
+				return
+			}
+			if redact {
+				if _, err := g.pasteApp.RedactPaste(pasteID); err != nil {
+					dialog.ShowError(fmt.Errorf("failed to redact paste: %v", err), g.mainWindow)
+					return
+				}
+				// Sharing again shouldn't ask about what is already gone
+				view.Title, view.Content = redactSecrets(view.Title, view.Content)
+				g.reloadPastes()
+			}
+
+			if err := g.pasteApp.SharePaste(pasteID, recipient); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to share paste: %v", err), g.mainWindow)
+				return
+			}
+
+			dialog.ShowInformation("Shared", fmt.Sprintf("The paste is now shared with %s.", recipient.Email), g.mainWindow)
+		}()
+	}, g.mainWindow)
+}
+
+// createSharedPastesTab creates the tab listing pastes shared with the user
+func (g *GUI) createSharedPastesTab() fyne.CanvasObject {
+	refreshBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), nil)
+	refreshBtn.Importance = widget.MediumImportance
+
+	heading := widget.NewLabelWithStyle(
+		"Shared With Me",
+		fyne.TextAlignLeading,
+		fyne.TextStyle{Bold: true},
+	)
+
+	// Titles are decrypted up front, so the list works on plain rows
+	type sharedRow struct {
+		paste *models.SharedPaste
+		title string
+	}
+	var rows []sharedRow
+
+	list := widget.NewList(
+		func() int { return len(rows) },
+		func() fyne.CanvasObject {
+			titleLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			ownerLabel := widget.NewLabelWithStyle("", fyne.TextAlignTrailing, fyne.TextStyle{})
+
+			return container.NewBorder(
+				nil, nil, nil, ownerLabel,
+				titleLabel,
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(rows) {
+				return
+			}
+
+			border := obj.(*fyne.Container)
+			border.Objects[0].(*widget.Label).SetText(rows[id].title)
+			border.Objects[1].(*widget.Label).SetText(rows[id].paste.OwnerEmail)
+		},
+	)
+
+	list.OnSelected = func(id widget.ListItemID) {
+		if id >= 0 && id < len(rows) {
+			g.showSharedPasteDetails(rows[id].paste)
+		}
+		list.UnselectAll()
+	}
+
+	statusLabel := widget.NewLabelWithStyle(
+		"",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Italic: true},
+	)
+
+	refresh := func() {
+		statusLabel.SetText("Loading shared pastes...")
+		statusLabel.Show()
+
+		go func() {
+			shared, err := g.pasteApp.GetSharedPastes()
+			if err != nil {
+				statusLabel.SetText(fmt.Sprintf("Failed to load shared pastes: %v", err))
+				return
+			}
+
+			loaded := make([]sharedRow, 0, len(shared))
+			for _, paste := range shared {
+				title := "(unable to decrypt)"
+				if view, err := g.pasteApp.DecryptSharedPaste(paste); err == nil {
+					title = view.Title
+				}
+				loaded = append(loaded, sharedRow{paste: paste, title: title})
+			}
+
+			rows = loaded
+			list.Refresh()
+
+			if len(rows) == 0 {
+				statusLabel.SetText("Nothing has been shared with you yet.")
+				return
+			}
+			statusLabel.Hide()
+		}()
+	}
+
+	refreshBtn.OnTapped = refresh
+	refresh()
+
+	openLinkBtn := widget.NewButtonWithIcon("Open Link", theme.ContentPasteIcon(), func() {
+		g.showOpenLinkDialog()
+	})
+
+	header := container.NewBorder(
+		nil, nil, heading, container.NewHBox(openLinkBtn, refreshBtn),
+		widget.NewSeparator(),
+	)
+
+	return container.NewBorder(
+		header,
+		nil, nil, nil,
+		container.NewStack(list, container.NewCenter(statusLabel)),
+	)
+}
+
+// showSharedPasteDetails shows a paste shared by another user
+func (g *GUI) showSharedPasteDetails(shared *models.SharedPaste) {
+	go func() {
+		view, err := g.pasteApp.DecryptSharedPaste(shared)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to decrypt paste: %v", err), g.mainWindow)
+			return
+		}
+
+		// Signature problems are shown rather than blocking the paste
+		author, err := g.pasteApp.VerifySharedPaste(shared)
+		if err != nil {
+			author = &core.PasteAuthor{UserID: shared.OwnerID, Email: shared.OwnerEmail}
+		}
+
+		contentView := container.NewVBox(
+			widget.NewLabelWithStyle(view.Title, fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			widget.NewLabel(fmt.Sprintf("Shared by %s on %s", shared.OwnerEmail, shared.SharedAt.Format(time.RFC822))),
+			authorLabel(author),
+			languageLabel(view.Language),
+			widget.NewSeparator(),
+			pasteBodyView(view),
+			container.NewHBox(
+				g.copyButton("Copy Content", func() (string, error) {
+					return view.Content, nil
+				}),
+			),
+		)
+
+		dialog.ShowCustom("Shared Paste", "Close", contentView, g.mainWindow)
+	}()
+}
+
+// showOpenLinkDialog asks for a share link, filled in from the clipboard
+// when it holds one, and shows the paste it opens
+func (g *GUI) showOpenLinkDialog() {
+	linkEntry := widget.NewEntry()
+	linkEntry.SetPlaceHolder("pastepal://paste/...")
+	if copied := strings.TrimSpace(g.mainWindow.Clipboard().Content()); strings.HasPrefix(copied, "pastepal://") {
+		linkEntry.SetText(copied)
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Link", linkEntry),
+	}
+
+	dialog.ShowForm("Open Share Link", "Open", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+
+		go func() {
+			view, err := g.pasteApp.OpenShareLink(linkEntry.Text)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to open link: %v", err), g.mainWindow)
+				return
+			}
+
+			contentView := container.NewVBox(
+				widget.NewLabelWithStyle(view.Title, fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+				authorLabel(view.Author),
+				languageLabel(view.Language),
+				widget.NewSeparator(),
+				pasteBodyView(view),
+				container.NewHBox(
+					g.copyButton("Copy Content", func() (string, error) {
+						return view.Content, nil
+					}),
+				),
+			)
+
+			dialog.ShowCustom("Linked Paste", "Close", contentView, g.mainWindow)
+		}()
+	}, g.mainWindow)
+}
+
+// createSharingKeyCard shows the user's key fingerprint so people sharing
+// with them can confirm it
+func (g *GUI) createSharingKeyCard() fyne.CanvasObject {
+	heading := widget.NewLabelWithStyle(
+		"Sharing Key",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	fingerprintLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	fingerprintLabel.Wrapping = fyne.TextWrapWord
+
+	fingerprint, err := g.pasteApp.PublicKeyFingerprint()
+	if err != nil {
+		fingerprintLabel.SetText("Not available")
+	} else {
+		fingerprintLabel.SetText(fingerprint)
+	}
+
+	signingLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	signingLabel.Wrapping = fyne.TextWrapWord
+
+	signingFingerprint, err := g.pasteApp.SigningKeyFingerprint()
+	if err != nil {
+		signingLabel.SetText("Not available")
+	} else {
+		signingLabel.SetText(signingFingerprint)
+	}
+
+	return container.NewVBox(
+		heading,
+		widget.NewSeparator(),
+		container.NewPadded(widget.NewLabel("People sharing pastes with you will be asked to confirm this fingerprint:")),
+		container.NewPadded(fingerprintLabel),
+		container.NewPadded(widget.NewLabel("Pastes you sign show this signing key fingerprint:")),
+		container.NewPadded(signingLabel),
+	)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+)
+
+// authorLabel describes who wrote a paste and whether their signature holds
+func authorLabel(author *core.PasteAuthor) fyne.CanvasObject {
+	name := author.Email
+	if name == "" {
+		name = "an unknown author"
+	}
+
+	var text string
+	style := fyne.TextStyle{}
+	switch author.Status {
+	case core.SignatureVerified:
+		text = fmt.Sprintf("Signed by %s (key %s)", name, author.Fingerprint)
+	case core.SignatureInvalid:
+		text = fmt.Sprintf("WARNING: The signature from %s does not match. This paste or its details were altered.", name)
+		style.Bold = true
+	default:
+		text = fmt.Sprintf("Not signed. Claimed author: %s", name)
+		style.Italic = true
+	}
+
+	label := widget.NewLabelWithStyle(text, fyne.TextAlignLeading, style)
+	label.Wrapping = fyne.TextWrapWord
+	if author.Status == core.SignatureInvalid {
+		label.Importance = widget.DangerImportance
+	}
+	return label
+}
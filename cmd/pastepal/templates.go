@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+)
+
+// Expiry picker options and the hours each stands for
+var expiryPresets = []struct {
+	label string
+	hours int
+}{
+	{"Never", 0},
+	{"1 hour", 1},
+	{"1 day", 24},
+	{"1 week", 7 * 24},
+	{"30 days", 30 * 24},
+}
+
+// expiryOptions lists the choices of the expiry picker
+func expiryOptions() []string {
+	options := make([]string, len(expiryPresets))
+	for i, preset := range expiryPresets {
+		options[i] = preset.label
+	}
+	return options
+}
+
+// setExpiry picks the expiry picker choice for hours, adding one when no
+// preset matches
+func setExpiry(expirySelect *widget.Select, hours int) {
+	for _, preset := range expiryPresets {
+		if preset.hours == hours {
+			expirySelect.SetSelected(preset.label)
+			return
+		}
+	}
+
+	option := fmt.Sprintf("%d hours", hours)
+	expirySelect.Options = append(expiryOptions(), option)
+	expirySelect.SetSelected(option)
+}
+
+// expiryFromOption maps an expiry picker choice to hours, 0 for never
+func expiryFromOption(option string) int {
+	for _, preset := range expiryPresets {
+		if preset.label == option {
+			return preset.hours
+		}
+	}
+
+	var hours int
+	fmt.Sscanf(option, "%d hours", &hours)
+	return hours
+}
+
+// loadTemplates fetches the user's templates and updates the template
+// picker
+func (g *GUI) loadTemplates() {
+	templates, err := g.pasteApp.GetTemplates()
+	if err != nil {
+		fmt.Printf("[GUI] Failed to load templates: %v\n", err)
+		return
+	}
+
+	g.templates = templates
+	if g.templateSelect == nil {
+		return
+	}
+
+	names := make([]string, len(templates))
+	for i, template := range templates {
+		names[i] = template.Name
+	}
+	g.templateSelect.Options = names
+	g.templateSelect.Refresh()
+}
+
+// findTemplate returns the loaded template with the given name, ignoring
+// case
+func (g *GUI) findTemplate(name string) *core.Template {
+	for _, template := range g.templates {
+		if strings.EqualFold(template.Name, name) {
+			return template
+		}
+	}
+	return nil
+}
+
+// createTemplateBar creates the template picker of the create tab, with
+// buttons to save the form as a template and to manage templates
+func (g *GUI) createTemplateBar() fyne.CanvasObject {
+	g.templateSelect = widget.NewSelect(nil, nil)
+	g.templateSelect.PlaceHolder = "Start from a template..."
+	g.templateSelect.OnChanged = func(name string) {
+		if name == "" {
+			return
+		}
+		g.templateSelect.ClearSelected()
+
+		if template := g.findTemplate(name); template != nil {
+			g.useTemplate(template)
+		}
+	}
+
+	saveButton := widget.NewButtonWithIcon("Save as Template", theme.DocumentSaveIcon(), func() {
+		g.showSaveTemplateDialog()
+	})
+	manageButton := widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
+		g.showManageTemplatesDialog()
+	})
+
+	go g.loadTemplates()
+
+	return container.NewBorder(nil, nil,
+		widget.NewLabelWithStyle("Template", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewHBox(saveButton, manageButton),
+		g.templateSelect,
+	)
+}
+
+// useTemplate asks for the template's placeholders and starts a new draft
+// from it
+func (g *GUI) useTemplate(template *core.Template) {
+	start := func(values map[string]string) {
+		title, content, err := template.Fill(values)
+		if err != nil {
+			dialog.ShowError(err, g.mainWindow)
+			return
+		}
+
+		// The draft being written is kept, and the template starts a new one.
+		// Templates such as credential handoffs put what is filled in next
+		// to their own text, so the draft starts with "Never compress"
+		// checked.
+		g.openDraft(&core.Draft{
+			Title:       title,
+			Content:     content,
+			Language:    template.Language,
+			Format:      template.Format,
+			Tags:        template.Tags,
+			IsPublic:    template.IsPublic,
+			Sign:        true,
+			NoCompress:  true,
+			ExpiryHours: template.ExpiryHours,
+		})
+	}
+
+	placeholders := template.Placeholders()
+	if len(placeholders) == 0 {
+		start(nil)
+		return
+	}
+
+	entries := make(map[string]*widget.Entry, len(placeholders))
+	items := make([]*widget.FormItem, len(placeholders))
+	for i, name := range placeholders {
+		entry := widget.NewEntry()
+		entries[name] = entry
+		items[i] = widget.NewFormItem(name, entry)
+	}
+
+	dialog.ShowForm(template.Name, "Use Template", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+
+		values := make(map[string]string, len(entries))
+		for name, entry := range entries {
+			values[name] = entry.Text
+		}
+		start(values)
+	}, g.mainWindow)
+}
+
+// showSaveTemplateDialog saves the create tab's form as a template under a
+// name, replacing the template of that name if there is one
+func (g *GUI) showSaveTemplateDialog() {
+	if g.draftEditor == nil {
+		return
+	}
+	form := g.draftEditor.read()
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("e.g. Incident summary")
+
+	hint := widget.NewLabel("Use {{name}} in the title or content for text filled in\n" +
+		"when the template is used. {{date}} and {{time}} are filled in for you.")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("", hint),
+	}
+
+	dialog.ShowForm("Save as Template", "Save", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+
+		template := &core.Template{
+			Name:        nameEntry.Text,
+			Title:       form.Title,
+			Content:     form.Content,
+			Language:    form.Language,
+			Format:      form.Format,
+			Tags:        form.Tags,
+			IsPublic:    form.IsPublic,
+			ExpiryHours: form.ExpiryHours,
+		}
+
+		save := func() {
+			go func() {
+				if err := g.pasteApp.SaveTemplate(template); err != nil {
+					dialog.ShowError(fmt.Errorf("failed to save template: %v", err), g.mainWindow)
+					return
+				}
+				g.loadTemplates()
+				dialog.ShowInformation("Save as Template", fmt.Sprintf("Saved template %q.", template.Name), g.mainWindow)
+			}()
+		}
+
+		existing := g.findTemplate(strings.TrimSpace(nameEntry.Text))
+		if existing == nil {
+			save()
+			return
+		}
+
+		dialog.ShowConfirm("Replace Template", fmt.Sprintf("Replace the template %q?", existing.Name), func(replace bool) {
+			if replace {
+				template.ID = existing.ID
+				save()
+			}
+		}, g.mainWindow)
+	}, g.mainWindow)
+}
+
+// showManageTemplatesDialog lists the user's templates for deleting them
+func (g *GUI) showManageTemplatesDialog() {
+	emptyLabel := widget.NewLabelWithStyle(
+		"No templates yet. Fill in the form and use \"Save as Template\".",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Italic: true},
+	)
+	if len(g.templates) > 0 {
+		emptyLabel.Hide()
+	}
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int { return len(g.templates) },
+		func() fyne.CanvasObject {
+			nameLabel := widget.NewLabel("")
+			deleteButton := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), nil)
+			return container.NewBorder(nil, nil, nil, deleteButton, nameLabel)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(g.templates) {
+				return
+			}
+
+			template := g.templates[id]
+			border := obj.(*fyne.Container)
+			border.Objects[0].(*widget.Label).SetText(template.Name)
+			border.Objects[1].(*widget.Button).OnTapped = func() {
+				dialog.ShowConfirm("Delete Template", fmt.Sprintf("Delete the template %q?\nPastes made from it are kept.", template.Name), func(confirm bool) {
+					if !confirm {
+						return
+					}
+
+					go func() {
+						if err := g.pasteApp.DeleteTemplate(template.ID); err != nil {
+							dialog.ShowError(fmt.Errorf("failed to delete template: %v", err), g.mainWindow)
+							return
+						}
+						g.loadTemplates()
+						list.Refresh()
+						if len(g.templates) == 0 {
+							emptyLabel.Show()
+						}
+					}()
+				}, g.mainWindow)
+			}
+		},
+	)
+
+	content := container.NewStack(list, container.NewCenter(emptyLabel))
+	manage := dialog.NewCustom("Templates", "Close", content, g.mainWindow)
+	manage.Resize(fyne.NewSize(450, 350))
+	manage.Show()
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+)
+
+// trashPaste moves a paste to the trash and offers to undo it
+func (g *GUI) trashPaste(pasteID, title string) {
+	go func() {
+		if err := g.pasteApp.TrashPaste(pasteID); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to move paste to the trash: %v", err), g.mainWindow)
+			return
+		}
+		g.reloadPastes()
+
+		message := fmt.Sprintf("%q was moved to the trash.\nIt is deleted for good after %d days.", title, g.trashRetentionDays())
+		undo := dialog.NewConfirm("Moved to Trash", message, func(undo bool) {
+			if !undo {
+				return
+			}
+
+			go func() {
+				if err := g.pasteApp.RestorePaste(pasteID); err != nil {
+					dialog.ShowError(fmt.Errorf("failed to restore paste: %v", err), g.mainWindow)
+					return
+				}
+				g.reloadPastes()
+			}()
+		}, g.mainWindow)
+		undo.SetConfirmText("Undo")
+		undo.SetDismissText("Close")
+		undo.Show()
+	}()
+}
+
+// createTrashTab creates the tab listing deleted pastes, which can be
+// restored or deleted for good
+func (g *GUI) createTrashTab() fyne.CanvasObject {
+	refreshBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), nil)
+	refreshBtn.Importance = widget.MediumImportance
+
+	heading := widget.NewLabelWithStyle(
+		"Trash",
+		fyne.TextAlignLeading,
+		fyne.TextStyle{Bold: true},
+	)
+
+	var trashed []*core.TrashedPaste
+
+	list := widget.NewList(
+		func() int { return len(trashed) },
+		func() fyne.CanvasObject {
+			titleLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			purgeLabel := widget.NewLabelWithStyle("", fyne.TextAlignTrailing, fyne.TextStyle{Italic: true})
+			restoreButton := widget.NewButtonWithIcon("Restore", theme.ContentUndoIcon(), nil)
+			deleteButton := widget.NewButtonWithIcon("Delete Forever", theme.DeleteIcon(), nil)
+			deleteButton.Importance = widget.DangerImportance
+
+			return container.NewBorder(
+				nil, nil, nil, container.NewHBox(purgeLabel, restoreButton, deleteButton),
+				titleLabel,
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(trashed) {
+				return
+			}
+
+			entry := trashed[id]
+			border := obj.(*fyne.Container)
+			border.Objects[0].(*widget.Label).SetText(entry.Title)
+
+			trailing := border.Objects[1].(*fyne.Container)
+			trailing.Objects[0].(*widget.Label).SetText(purgeText(entry.PurgeAt))
+			trailing.Objects[1].(*widget.Button).OnTapped = func() {
+				g.restorePaste(entry)
+			}
+			trailing.Objects[2].(*widget.Button).OnTapped = func() {
+				g.confirmDeleteForever(entry)
+			}
+		},
+	)
+
+	statusLabel := widget.NewLabelWithStyle(
+		"",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Italic: true},
+	)
+
+	infoLabel := widget.NewLabel("")
+
+	g.refreshTrash = func() {
+		infoLabel.SetText(fmt.Sprintf(
+			"Deleted pastes stay here for %d days before they are deleted for good.",
+			g.trashRetentionDays(),
+		))
+		statusLabel.SetText("Loading the trash...")
+		statusLabel.Show()
+
+		go func() {
+			loaded, err := g.pasteApp.GetTrash()
+			if err != nil {
+				statusLabel.SetText(fmt.Sprintf("Failed to load the trash: %v", err))
+				return
+			}
+
+			trashed = loaded
+			list.Refresh()
+
+			if len(trashed) == 0 {
+				statusLabel.SetText("The trash is empty.")
+				return
+			}
+			statusLabel.Hide()
+		}()
+	}
+	refreshBtn.OnTapped = g.refreshTrash
+
+	emptyBtn := widget.NewButtonWithIcon("Empty Trash", theme.DeleteIcon(), func() {
+		g.confirmEmptyTrash()
+	})
+	emptyBtn.Importance = widget.DangerImportance
+
+	header := container.NewBorder(
+		nil, nil, heading, container.NewHBox(emptyBtn, refreshBtn),
+		widget.NewSeparator(),
+	)
+
+	return container.NewBorder(
+		container.NewVBox(header, infoLabel),
+		nil, nil, nil,
+		container.NewStack(list, container.NewCenter(statusLabel)),
+	)
+}
+
+// trashRetentionDays returns how long the account keeps deleted pastes
+func (g *GUI) trashRetentionDays() int {
+	settings, err := g.pasteApp.GetAccountSettings()
+	if err != nil {
+		return core.DefaultAccountSettings().TrashRetentionDays
+	}
+	return settings.TrashRetentionDays
+}
+
+// purgeText says when a paste in the trash is deleted for good
+func purgeText(purgeAt time.Time) string {
+	if purgeAt.IsZero() {
+		return ""
+	}
+
+	days := int(time.Until(purgeAt).Hours() / 24)
+	switch {
+	case days < 1:
+		return "Deleted within a day"
+	case days == 1:
+		return "Deleted in 1 day"
+	default:
+		return fmt.Sprintf("Deleted in %d days", days)
+	}
+}
+
+// restorePaste moves a paste back out of the trash
+func (g *GUI) restorePaste(entry *core.TrashedPaste) {
+	go func() {
+		if err := g.pasteApp.RestorePaste(entry.Paste.ID); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to restore paste: %v", err), g.mainWindow)
+			return
+		}
+
+		g.refreshTrash()
+		g.reloadPastes()
+	}()
+}
+
+// confirmDeleteForever deletes a paste in the trash for good once the user
+// confirms it
+func (g *GUI) confirmDeleteForever(entry *core.TrashedPaste) {
+	confirm := dialog.NewConfirm(
+		"Delete Forever",
+		fmt.Sprintf("Permanently delete %q?\nIt is removed from the server and this device and can't be restored.", entry.Title),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			go func() {
+				if err := g.pasteApp.DeletePastePermanently(entry.Paste.ID); err != nil {
+					dialog.ShowError(fmt.Errorf("failed to delete paste: %v", err), g.mainWindow)
+				}
+				g.refreshTrash()
+			}()
+		},
+		g.mainWindow,
+	)
+	confirm.SetConfirmText("Delete Forever")
+	confirm.Show()
+}
+
+// confirmEmptyTrash deletes every paste in the trash for good once the user
+// confirms it
+func (g *GUI) confirmEmptyTrash() {
+	confirm := dialog.NewConfirm(
+		"Empty Trash",
+		"Permanently delete every paste in the trash?\nThey can't be restored.",
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			go func() {
+				count, err := g.pasteApp.EmptyTrash()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("deleted %d pastes, then failed: %v", count, err), g.mainWindow)
+				}
+				g.refreshTrash()
+			}()
+		},
+		g.mainWindow,
+	)
+	confirm.SetConfirmText("Empty Trash")
+	confirm.Show()
+}
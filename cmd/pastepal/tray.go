@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+)
+
+// How many pastes the tray menu lists
+const trayRecentPastes = 5
+
+// setupTray puts PastePal in the system tray where the desktop has one.
+// Closing the window then hides it there instead of quitting.
+func (g *GUI) setupTray() {
+	tray, ok := g.app.(desktop.App)
+	if !ok || !g.pasteApp.GetConfig().SystemTray {
+		return
+	}
+
+	g.tray = tray
+	tray.SetSystemTrayIcon(theme.ContentPasteIcon())
+	g.updateTrayMenu()
+
+	g.mainWindow.SetCloseIntercept(func() {
+		g.mainWindow.Hide()
+	})
+}
+
+// updateTrayMenu rebuilds the tray menu. Recent pastes are only listed
+// while the vault is open, so their titles aren't shown when locked.
+func (g *GUI) updateTrayMenu() {
+	if g.tray == nil {
+		return
+	}
+
+	var recentItems []*fyne.MenuItem
+	if g.pasteApp.IsLoggedIn && !g.pasteApp.IsLocked() {
+		recent, err := g.pasteApp.RecentPastes(g.pastes, trayRecentPastes)
+		if err != nil {
+			fmt.Printf("[GUI] Failed to list recent pastes: %v\n", err)
+		}
+		for _, r := range recent {
+			paste := r.Paste
+			recentItems = append(recentItems, fyne.NewMenuItem(r.Title, func() {
+				g.showWindow()
+				g.showPasteDetails(paste)
+			}))
+		}
+	}
+	if len(recentItems) == 0 {
+		label := "No pastes loaded"
+		if !g.pasteApp.IsLoggedIn || g.pasteApp.IsLocked() {
+			label = "Unlock to see recent pastes"
+		}
+		empty := fyne.NewMenuItem(label, nil)
+		empty.Disabled = true
+		recentItems = append(recentItems, empty)
+	}
+
+	recentMenu := fyne.NewMenuItem("Recent Pastes", nil)
+	recentMenu.ChildMenu = fyne.NewMenu("", recentItems...)
+
+	lockItem := fyne.NewMenuItem("Lock", func() {
+		go g.lockVault()
+	})
+	lockItem.Disabled = !g.pasteApp.IsLoggedIn || g.pasteApp.IsLocked()
+
+	quitItem := fyne.NewMenuItem("Quit", func() {
+		g.saveDraft()
+		g.app.Quit()
+	})
+	quitItem.IsQuit = true
+
+	g.tray.SetSystemTrayMenu(fyne.NewMenu("PastePal",
+		fyne.NewMenuItem("Show PastePal", g.showWindow),
+		fyne.NewMenuItem("New Paste from Clipboard", g.newPasteFromClipboard),
+		fyne.NewMenuItem("Quick Capture", g.quickCapture),
+		fyne.NewMenuItemSeparator(),
+		recentMenu,
+		fyne.NewMenuItemSeparator(),
+		lockItem,
+		quitItem,
+	))
+}
+
+// showWindow brings the main window back from the tray
+func (g *GUI) showWindow() {
+	g.mainWindow.Show()
+	g.mainWindow.RequestFocus()
+}
+
+// newPasteFromClipboard opens the create tab filled in from the clipboard
+func (g *GUI) newPasteFromClipboard() {
+	g.showWindow()
+	if !g.pasteApp.IsLoggedIn || g.pasteApp.IsLocked() || g.fillFromClipboard == nil {
+		return
+	}
+
+	g.tabs.SelectIndex(createPasteTab)
+	g.fillFromClipboard()
+}
+
+// quickCapture makes a public paste of the clipboard with the quick capture
+// expiry, and replaces the clipboard with its share link
+func (g *GUI) quickCapture() {
+	if !g.pasteApp.IsLoggedIn || g.pasteApp.IsLocked() {
+		g.notify("Unlock PastePal to quick capture the clipboard.")
+		g.showWindow()
+		return
+	}
+
+	content := g.mainWindow.Clipboard().Content()
+	if strings.TrimSpace(content) == "" {
+		g.notify("The clipboard doesn't hold any text.")
+		return
+	}
+
+	// There is no window to warn in, so the clipboard has to be reviewed
+	// before it is shared
+	if found := describeSecrets("", content); len(found) > 0 {
+		g.notify(fmt.Sprintf("The clipboard looks like it contains %d secrets, so it wasn't shared. Use New Paste from Clipboard to review it.", len(found)))
+		return
+	}
+
+	go func() {
+		g.pasteApp.RecordActivity()
+
+		_, link, err := g.pasteApp.QuickCapture(content)
+		if err != nil {
+			g.notify(fmt.Sprintf("Quick capture failed: %v", err))
+			return
+		}
+		g.copySecret(link)
+
+		message := fmt.Sprintf("Share link copied. The paste expires in %d hours.", g.pasteApp.GetConfig().QuickCaptureExpiryHours)
+		g.notify(message)
+	}()
+}
+
+// notify shows a desktop notification, since the window may be hidden
+func (g *GUI) notify(message string) {
+	g.app.SendNotification(fyne.NewNotification("PastePal", message))
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showTwoFactorLoginPrompt asks for the second factor of a pending login
+func (g *GUI) showTwoFactorLoginPrompt(statusLabel *widget.Label) {
+	codeEntry := widget.NewEntry()
+	codeEntry.SetPlaceHolder("123456")
+
+	recoveryCheck := widget.NewCheck("Use a recovery code instead", func(checked bool) {
+		if checked {
+			codeEntry.SetPlaceHolder("Recovery code")
+		} else {
+			codeEntry.SetPlaceHolder("123456")
+		}
+	})
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Code", codeEntry),
+		widget.NewFormItem("", recoveryCheck),
+	}
+
+	dialog.ShowForm("Two-Factor Authentication", "Verify", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			g.pasteApp.CancelTwoFactorLogin()
+			statusLabel.Hide()
+			return
+		}
+
+		code := strings.TrimSpace(codeEntry.Text)
+		if code == "" {
+			dialog.ShowError(fmt.Errorf("a code is required"), g.mainWindow)
+			g.showTwoFactorLoginPrompt(statusLabel)
+			return
+		}
+
+		statusLabel.SetText("Verifying code...")
+		statusLabel.Show()
+
+		go func() {
+			err := g.pasteApp.CompleteTwoFactorLogin(code, recoveryCheck.Checked)
+
+			// Update UI in a goroutine-safe way
+			g.mainWindow.Canvas().Refresh(g.currentContainer)
+			if err != nil {
+				statusLabel.SetText(fmt.Sprintf("Verification failed: %v", err))
+				g.showTwoFactorLoginPrompt(statusLabel)
+				return
+			}
+
+			statusLabel.Hide()
+			g.showDashboard()
+		}()
+	}, g.mainWindow)
+}
+
+// createTwoFactorCard creates the two-factor section of the account tab
+func (g *GUI) createTwoFactorCard() fyne.CanvasObject {
+	heading := widget.NewLabelWithStyle(
+		"Two-Factor Authentication",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	statusLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{})
+
+	var enableButton, disableButton *widget.Button
+
+	refresh := func() {
+		if g.pasteApp.CurrentUser != nil && g.pasteApp.CurrentUser.TwoFactorEnabled {
+			statusLabel.SetText("Status: Enabled")
+			enableButton.Hide()
+			disableButton.Show()
+		} else {
+			statusLabel.SetText("Status: Disabled")
+			enableButton.Show()
+			disableButton.Hide()
+		}
+	}
+
+	enableButton = widget.NewButton("Enable Two-Factor Authentication", func() {
+		g.showTwoFactorSetup(refresh)
+	})
+	enableButton.Importance = widget.HighImportance
+
+	disableButton = widget.NewButton("Disable Two-Factor Authentication", func() {
+		g.showTwoFactorDisable(refresh)
+	})
+
+	refresh()
+
+	return container.NewVBox(
+		heading,
+		widget.NewSeparator(),
+		container.NewPadded(statusLabel),
+		container.NewPadded(container.NewHBox(enableButton, disableButton)),
+	)
+}
+
+// showTwoFactorSetup walks the user through enrolling an authenticator app
+func (g *GUI) showTwoFactorSetup(onDone func()) {
+	progress := dialog.NewProgress("Two-Factor Authentication", "Generating secret...", g.mainWindow)
+	progress.Show()
+
+	go func() {
+		setup, err := g.pasteApp.BeginTwoFactorSetup()
+		progress.Hide()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to start two-factor setup: %v", err), g.mainWindow)
+			return
+		}
+
+		// Read-only but selectable, so the values can be copied
+		secretEntry := widget.NewEntry()
+		secretEntry.SetText(setup.Secret)
+		secretEntry.Disable()
+
+		uriEntry := widget.NewMultiLineEntry()
+		uriEntry.SetText(setup.ProvisioningURI)
+		uriEntry.Wrapping = fyne.TextWrapBreak
+		uriEntry.Disable()
+
+		codeEntry := widget.NewEntry()
+		codeEntry.SetPlaceHolder("Code from your authenticator app")
+
+		items := []*widget.FormItem{
+			widget.NewFormItem("", widget.NewLabel("Add this account to your authenticator app, then enter the code it shows.")),
+			widget.NewFormItem("Secret", secretEntry),
+			widget.NewFormItem("URI", uriEntry),
+			widget.NewFormItem("Code", codeEntry),
+		}
+
+		form := dialog.NewForm("Enable Two-Factor Authentication", "Enable", "Cancel", items, func(submitted bool) {
+			if !submitted {
+				return
+			}
+
+			go func() {
+				recoveryCodes, err := g.pasteApp.EnableTwoFactor(codeEntry.Text)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("failed to enable two-factor authentication: %v", err), g.mainWindow)
+					return
+				}
+
+				onDone()
+				g.showRecoveryCodes(recoveryCodes)
+			}()
+		}, g.mainWindow)
+		form.Resize(fyne.NewSize(520, 360))
+		form.Show()
+	}()
+}
+
+// showRecoveryCodes displays the one-time recovery codes after enrollment
+func (g *GUI) showRecoveryCodes(recoveryCodes []string) {
+	codesEntry := widget.NewMultiLineEntry()
+	codesEntry.SetText(strings.Join(recoveryCodes, "\n"))
+	codesEntry.Disable()
+
+	content := container.NewVBox(
+		widget.NewLabel("Store these recovery codes somewhere safe. Each code works once\nand they will not be shown again."),
+		codesEntry,
+	)
+
+	dialog.ShowCustom("Recovery Codes", "I have saved them", content, g.mainWindow)
+}
+
+// showTwoFactorDisable asks for a code and turns two-factor authentication off
+func (g *GUI) showTwoFactorDisable(onDone func()) {
+	codeEntry := widget.NewEntry()
+	codeEntry.SetPlaceHolder("123456")
+	recoveryCheck := widget.NewCheck("Use a recovery code instead", nil)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Code", codeEntry),
+		widget.NewFormItem("", recoveryCheck),
+	}
+
+	dialog.ShowForm("Disable Two-Factor Authentication", "Disable", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+
+		go func() {
+			if err := g.pasteApp.DisableTwoFactor(codeEntry.Text, recoveryCheck.Checked); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to disable two-factor authentication: %v", err), g.mainWindow)
+				return
+			}
+
+			onDone()
+			dialog.ShowInformation("Two-Factor Authentication", "Two-factor authentication has been disabled.", g.mainWindow)
+		}()
+	}, g.mainWindow)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/core"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+	"github.com/JacobRWebb/PastePal-OS/internal/syntax"
+)
+
+// Language picker options that aren't language names
+const (
+	languageAutoDetect = "Auto-detect"
+	languagePlainText  = "Plain text"
+)
+
+// languageOptions lists the choices of the language picker
+func languageOptions() []string {
+	return append([]string{languageAutoDetect, languagePlainText}, syntax.CommonLanguages...)
+}
+
+// languageFromOption maps a language picker choice to the language passed
+// to the app, where "" means detect it
+func languageFromOption(option string) string {
+	switch option {
+	case languageAutoDetect:
+		return ""
+	case languagePlainText:
+		return syntax.PlainText
+	default:
+		return option
+	}
+}
+
+// languageOption maps a language back to its language picker choice
+func languageOption(language string) string {
+	switch language {
+	case "":
+		return languageAutoDetect
+	case syntax.PlainText:
+		return languagePlainText
+	default:
+		return language
+	}
+}
+
+// Format picker options
+const (
+	formatAuto     = "Auto"
+	formatPlain    = "Plain text"
+	formatCode     = "Code"
+	formatMarkdown = "Markdown"
+)
+
+// formatOptions lists the choices of the format picker
+func formatOptions() []string {
+	return []string{formatAuto, formatPlain, formatCode, formatMarkdown}
+}
+
+// formatFromOption maps a format picker choice to the format passed to the
+// app, where "" means pick one to suit the language
+func formatFromOption(option string) string {
+	switch option {
+	case formatPlain:
+		return models.PasteFormatPlain
+	case formatCode:
+		return models.PasteFormatCode
+	case formatMarkdown:
+		return models.PasteFormatMarkdown
+	default:
+		return ""
+	}
+}
+
+// formatOption maps a format back to its format picker choice
+func formatOption(format string) string {
+	switch format {
+	case models.PasteFormatPlain:
+		return formatPlain
+	case models.PasteFormatCode:
+		return formatCode
+	case models.PasteFormatMarkdown:
+		return formatMarkdown
+	default:
+		return formatAuto
+	}
+}
+
+// classColors are the theme colors each kind of token is drawn in
+var classColors = map[syntax.Class]fyne.ThemeColorName{
+	syntax.ClassKeyword:  theme.ColorNamePrimary,
+	syntax.ClassName:     theme.ColorNameHyperlink,
+	syntax.ClassString:   theme.ColorNameSuccess,
+	syntax.ClassNumber:   theme.ColorNameWarning,
+	syntax.ClassComment:  theme.ColorNamePlaceHolder,
+	syntax.ClassOperator: theme.ColorNameForeground,
+}
+
+// pasteContentView shows paste content read-only. Code is highlighted with
+// line numbers, and plain text is shown as is.
+func pasteContentView(content, language string) fyne.CanvasObject {
+	if language == "" || language == syntax.PlainText {
+		return container.NewScroll(widget.NewLabel(content))
+	}
+
+	grid := widget.NewTextGrid()
+	grid.ShowLineNumbers = true
+
+	styles := make(map[syntax.Class]widget.TextGridStyle)
+	styleFor := func(class syntax.Class) widget.TextGridStyle {
+		name, ok := classColors[class]
+		if !ok {
+			return nil
+		}
+		if style, ok := styles[class]; ok {
+			return style
+		}
+
+		style := &widget.CustomTextGridStyle{FGColor: theme.Color(name)}
+		styles[class] = style
+		return style
+	}
+
+	var rows []widget.TextGridRow
+	for _, line := range syntax.Highlight(content, language) {
+		var cells []widget.TextGridCell
+		for _, span := range line {
+			style := styleFor(span.Class)
+			for _, r := range span.Text {
+				cells = append(cells, widget.TextGridCell{Rune: r, Style: style})
+			}
+		}
+		rows = append(rows, widget.TextGridRow{Cells: cells})
+	}
+	grid.Rows = rows
+
+	scroll := container.NewScroll(grid)
+	scroll.SetMinSize(fyne.NewSize(560, 320))
+	return scroll
+}
+
+// pasteBodyView shows a paste's content in its format. Markdown is rendered,
+// with a switch to show the source instead.
+func pasteBodyView(view *core.PasteView) fyne.CanvasObject {
+	language := view.Language
+	if view.Format == models.PasteFormatPlain {
+		language = syntax.PlainText
+	}
+	raw := pasteContentView(view.Content, language)
+	if view.Format != models.PasteFormatMarkdown {
+		return raw
+	}
+
+	rendered := markdownView(view.Content)
+	raw.Hide()
+
+	toggle := widget.NewCheck("Show raw", func(checked bool) {
+		if checked {
+			rendered.Hide()
+			raw.Show()
+		} else {
+			raw.Hide()
+			rendered.Show()
+		}
+	})
+
+	return container.NewBorder(toggle, nil, nil, nil, container.NewStack(rendered, raw))
+}
+
+// languageLabel names the language a paste is shown as
+func languageLabel(language string) *widget.Label {
+	if language == "" || language == syntax.PlainText {
+		language = languagePlainText
+	}
+	return widget.NewLabel("Language: " + language)
+}
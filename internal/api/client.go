@@ -7,31 +7,165 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/JacobRWebb/PastePal-OS/internal/config"
 	"github.com/JacobRWebb/PastePal-OS/internal/models"
 )
 
 // Client handles API communication with the server
 type Client struct {
-	BaseURL    string
-	HTTPClient *http.Client
-	AuthToken  string
+	BaseURL     string
+	HTTPClient  *http.Client
+	authToken   string
+	requestBase string
+	mutex       sync.RWMutex
+
+	// Cached result of login protocol negotiation
+	loginProtocols []string
 }
 
-// NewClient creates a new API client
-func NewClient(baseURL string) *Client {
-	return &Client{
-		BaseURL: baseURL,
-		HTTPClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+// StatusError is returned when the server answers with an unexpected status
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed (%d): %s", e.StatusCode, e.Message)
+}
+
+// NewClient creates a new API client from the application configuration
+func NewClient(cfg *config.Config) (*Client, error) {
+	client := &Client{
+		HTTPClient: &http.Client{},
+	}
+
+	if err := client.Configure(cfg); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Configure points the client at the configured server and rebuilds its
+// transport, timeout, TLS and proxy settings
+func (c *Client) Configure(cfg *config.Config) error {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// A different server may speak different protocols, and must never get
+	// the token another server issued
+	if cfg.APIURL != c.BaseURL {
+		c.loginProtocols = nil
+		c.authToken = ""
+	}
+
+	c.BaseURL = cfg.APIURL
+	c.requestBase = requestBase(cfg)
+
+	// Swap in a new client so requests already in flight keep theirs untouched
+	c.HTTPClient = &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(cfg.RequestTimeout) * time.Second,
+	}
+
+	return nil
+}
+
+// endpoint builds the full URL for an API path
+func (c *Client) endpoint(path string) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.requestBase + path
+}
+
+// do sends a request with the current HTTP client
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	c.mutex.RLock()
+	httpClient := c.HTTPClient
+	c.mutex.RUnlock()
+
+	return httpClient.Do(req)
+}
+
+// token returns the current authentication token, empty when logged out
+func (c *Client) token() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.authToken
+}
+
+// doJSON sends an authenticated JSON request and decodes the JSON response
+// into respBody when it is non-nil
+func (c *Client) doJSON(method, path string, reqBody, respBody interface{}, expectedStatus int) error {
+	return c.sendJSON(method, path, true, reqBody, respBody, expectedStatus)
+}
+
+// sendJSON sends a JSON request, attaching the auth token when authenticated
+// is set, and decodes the JSON response into respBody when it is non-nil
+func (c *Client) sendJSON(method, path string, authenticated bool, reqBody, respBody interface{}, expectedStatus int) error {
+	var token string
+	if authenticated {
+		token = c.token()
+		if token == "" {
+			return errors.New("not authenticated")
+		}
+	}
+
+	var body io.Reader
+	if reqBody != nil {
+		data, err := json.Marshal(reqBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequest(method, c.endpoint(path), body)
+	if err != nil {
+		return err
+	}
+
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authenticated {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		message, _ := io.ReadAll(resp.Body)
+		return &StatusError{StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(message))}
 	}
+
+	if respBody == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(respBody)
 }
 
 // SetAuthToken sets the authentication token for API requests
 func (c *Client) SetAuthToken(token string) {
-	c.AuthToken = token
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.authToken = token
 }
 
 // Register registers a new user with the server
@@ -41,13 +175,13 @@ func (c *Client) Register(data *models.RegistrationData) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/auth/register", c.BaseURL), bytes.NewBuffer(reqBody))
+	req, err := http.NewRequest("POST", c.endpoint("/api/auth/register"), bytes.NewBuffer(reqBody))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -68,18 +202,22 @@ func (c *Client) Login(loginReq *models.LoginRequest) (*models.LoginResponse, er
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/auth/login", c.BaseURL), bytes.NewBuffer(reqBody))
+	req, err := http.NewRequest("POST", c.endpoint("/api/auth/login"), bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusAccepted {
+		return nil, readTwoFactorChallenge(resp.Body)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, errors.New("authentication failed")
 	}
@@ -89,9 +227,14 @@ func (c *Client) Login(loginReq *models.LoginRequest) (*models.LoginResponse, er
 		return nil, err
 	}
 
+	return c.parseLoginResponse(resp, respBody)
+}
+
+// parseLoginResponse decodes a successful login and adopts its auth token
+func (c *Client) parseLoginResponse(resp *http.Response, respBody []byte) (*models.LoginResponse, error) {
 	loginResp := &models.LoginResponse{}
 
-	err = json.Unmarshal(respBody, loginResp)
+	err := json.Unmarshal(respBody, loginResp)
 	if err != nil {
 		var rawResp map[string]interface{}
 		if jsonErr := json.Unmarshal(respBody, &rawResp); jsonErr == nil {
@@ -130,7 +273,8 @@ func (c *Client) Login(loginReq *models.LoginRequest) (*models.LoginResponse, er
 
 // CreatePaste creates a new encrypted paste on the server
 func (c *Client) CreatePaste(pasteReq *models.CreatePasteRequest) (*models.Paste, error) {
-	if c.AuthToken == "" {
+	token := c.token()
+	if token == "" {
 		return nil, errors.New("not authenticated")
 	}
 
@@ -139,15 +283,15 @@ func (c *Client) CreatePaste(pasteReq *models.CreatePasteRequest) (*models.Paste
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/pastes", c.BaseURL), bytes.NewBuffer(reqBody))
+	req, err := http.NewRequest("POST", c.endpoint("/api/pastes"), bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", c.AuthToken)
+	req.Header.Set("Authorization", token)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -168,16 +312,16 @@ func (c *Client) CreatePaste(pasteReq *models.CreatePasteRequest) (*models.Paste
 // GetPaste retrieves an encrypted paste from the server
 func (c *Client) GetPaste(pasteID string) (*models.Paste, error) {
 	fmt.Println("[API Client] Getting paste with ID:", pasteID)
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/pastes/%s", c.BaseURL, pasteID), nil)
+	req, err := http.NewRequest("GET", c.endpoint("/api/pastes/"+pasteID), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if c.AuthToken != "" {
-		req.Header.Set("Authorization", c.AuthToken)
+	if token := c.token(); token != "" {
+		req.Header.Set("Authorization", token)
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -197,18 +341,19 @@ func (c *Client) GetPaste(pasteID string) (*models.Paste, error) {
 
 // GetUserPastes retrieves all pastes for the authenticated user
 func (c *Client) GetUserPastes() ([]*models.Paste, error) {
-	if c.AuthToken == "" {
+	token := c.token()
+	if token == "" {
 		return nil, errors.New("not authenticated")
 	}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/pastes", c.BaseURL), nil)
+	req, err := http.NewRequest("GET", c.endpoint("/api/pastes"), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", c.AuthToken)
+	req.Header.Set("Authorization", token)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -225,3 +370,13 @@ func (c *Client) GetUserPastes() ([]*models.Paste, error) {
 
 	return pastes, nil
 }
+
+// UpdatePaste replaces the encrypted fields of a paste owned by the user
+func (c *Client) UpdatePaste(pasteID string, updateReq *models.UpdatePasteRequest) (*models.Paste, error) {
+	var paste models.Paste
+	if err := c.doJSON("PUT", "/api/pastes/"+pasteID, updateReq, &paste, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return &paste, nil
+}
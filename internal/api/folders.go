@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// GetFolders retrieves all folders of the authenticated user
+func (c *Client) GetFolders() ([]*models.Folder, error) {
+	var folders []*models.Folder
+	if err := c.doJSON("GET", "/api/folders", nil, &folders, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// CreateFolder creates a new folder
+func (c *Client) CreateFolder(folderReq *models.CreateFolderRequest) (*models.Folder, error) {
+	var folder models.Folder
+	if err := c.doJSON("POST", "/api/folders", folderReq, &folder, http.StatusCreated); err != nil {
+		return nil, err
+	}
+
+	return &folder, nil
+}
+
+// UpdateFolder renames a folder or moves it under another parent
+func (c *Client) UpdateFolder(folderID string, updateReq *models.UpdateFolderRequest) (*models.Folder, error) {
+	var folder models.Folder
+	if err := c.doJSON("PUT", "/api/folders/"+url.PathEscape(folderID), updateReq, &folder, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return &folder, nil
+}
+
+// MovePaste moves a paste into a folder, or to the top level when folderID
+// is empty
+func (c *Client) MovePaste(pasteID, folderID string) error {
+	moveReq := &models.MovePasteRequest{FolderID: folderID}
+	return c.doJSON("PUT", "/api/pastes/"+url.PathEscape(pasteID)+"/folder", moveReq, nil, http.StatusNoContent)
+}
+
+// SetPasteTags replaces the encrypted tags of a paste
+func (c *Client) SetPasteTags(pasteID, encryptedTags string) error {
+	tagsReq := &models.PasteTagsRequest{Tags: encryptedTags}
+	return c.doJSON("PUT", "/api/pastes/"+url.PathEscape(pasteID)+"/tags", tagsReq, nil, http.StatusNoContent)
+}
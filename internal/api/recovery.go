@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// SetRecoveryKey stores a new recovery-wrapped symmetric key for the current
+// user, replacing any previous recovery key
+func (c *Client) SetRecoveryKey(data *models.RecoveryKeyData) error {
+	return c.doJSON("PUT", "/api/auth/recovery", data, nil, http.StatusNoContent)
+}
+
+// StartRecovery proves possession of the recovery key and returns the
+// recovery-wrapped symmetric key
+func (c *Client) StartRecovery(recoveryReq *models.RecoveryRequest) (*models.RecoveryResponse, error) {
+	var recoveryResp models.RecoveryResponse
+	err := c.sendJSON("POST", "/api/auth/recovery/start", false, recoveryReq, &recoveryResp, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recoveryResp, nil
+}
+
+// CompleteRecovery replaces the password hash and password-wrapped key
+func (c *Client) CompleteRecovery(resetReq *models.RecoveryResetRequest) error {
+	return c.sendJSON("POST", "/api/auth/recovery/complete", false, resetReq, nil, http.StatusNoContent)
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// GetAccountSettings retrieves the current user's encrypted account
+// settings. It returns an empty string for accounts that have none yet.
+func (c *Client) GetAccountSettings() (string, error) {
+	var settings models.AccountSettingsData
+	err := c.doJSON("GET", "/api/users/me/settings", nil, &settings, http.StatusOK)
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return settings.Data, nil
+}
+
+// SaveAccountSettings replaces the current user's encrypted account settings
+func (c *Client) SaveAccountSettings(data string) error {
+	return c.doJSON("PUT", "/api/users/me/settings", &models.AccountSettingsData{Data: data}, nil, http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// PublishKeyPair stores the current user's sharing identity on the server
+func (c *Client) PublishKeyPair(keyPair *models.KeyPairData) error {
+	return c.doJSON("PUT", "/api/users/me/keys", keyPair, nil, http.StatusNoContent)
+}
+
+// LookupPublicKey finds another user's public key by email
+func (c *Client) LookupPublicKey(email string) (*models.PublicKeyInfo, error) {
+	var info models.PublicKeyInfo
+	err := c.doJSON("GET", "/api/users/lookup?email="+url.QueryEscape(email), nil, &info, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// SharePaste gives another user access to a paste
+func (c *Client) SharePaste(pasteID string, shareReq *models.ShareRequest) error {
+	return c.doJSON("POST", "/api/pastes/"+url.PathEscape(pasteID)+"/shares", shareReq, nil, http.StatusCreated)
+}
+
+// GetSharedPastes retrieves the pastes other users have shared with the
+// current user
+func (c *Client) GetSharedPastes() ([]*models.SharedPaste, error) {
+	var shared []*models.SharedPaste
+	if err := c.doJSON("GET", "/api/shares", nil, &shared, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return shared, nil
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/auth"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// ErrSRPNotEnrolled is returned by LoginSRP when the account still uses a
+// password hash and has to log in with Login once to be upgraded
+var ErrSRPNotEnrolled = errors.New("account has no SRP verifier yet")
+
+// LoginProtocols asks the server which login protocols it supports. Servers
+// that predate protocol negotiation only support password hashes.
+func (c *Client) LoginProtocols() ([]string, error) {
+	c.mutex.RLock()
+	cached := c.loginProtocols
+	c.mutex.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	req, err := http.NewRequest("GET", c.endpoint("/api/auth/protocols"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	protocols := []string{auth.ProtocolPasswordHash}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var protocolsResp models.ProtocolsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&protocolsResp); err != nil {
+			return nil, err
+		}
+		if len(protocolsResp.LoginProtocols) > 0 {
+			protocols = protocolsResp.LoginProtocols
+		}
+	case http.StatusNotFound:
+		// Legacy server
+	default:
+		return nil, errors.New("failed to negotiate login protocol")
+	}
+
+	c.mutex.Lock()
+	c.loginProtocols = protocols
+	c.mutex.Unlock()
+
+	return protocols, nil
+}
+
+// SupportsSRP reports whether the server accepts SRP logins
+func (c *Client) SupportsSRP() (bool, error) {
+	protocols, err := c.LoginProtocols()
+	if err != nil {
+		return false, err
+	}
+
+	for _, protocol := range protocols {
+		if protocol == auth.ProtocolSRP {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LoginSRP authenticates with SRP-6a. Like Login, it returns a
+// TwoFactorRequiredError when the account needs a second factor.
+func (c *Client) LoginSRP(email string, srpClient *auth.SRPClient) (*models.LoginResponse, error) {
+	initReq := &models.SRPInitRequest{Email: email, A: srpClient.PublicKey()}
+
+	var initResp models.SRPInitResponse
+	if err := c.sendJSON("POST", "/api/auth/srp/init", false, initReq, &initResp, http.StatusOK); err != nil {
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) {
+			return nil, err
+		}
+		if statusErr.StatusCode == http.StatusConflict {
+			return nil, ErrSRPNotEnrolled
+		}
+		return nil, errors.New("authentication failed")
+	}
+
+	clientProof, err := srpClient.ProcessChallenge(initResp.Salt, initResp.B)
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody, err := json.Marshal(&models.SRPVerifyRequest{SessionID: initResp.SessionID, M1: clientProof})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.endpoint("/api/auth/srp/verify"), bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusAccepted {
+		return nil, readTwoFactorChallenge(resp.Body)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("authentication failed")
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	loginResp, err := c.parseLoginResponse(resp, respBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't trust a server that can't prove it holds our verifier
+	if err := srpClient.VerifyServerProof(loginResp.ServerProof); err != nil {
+		c.SetAuthToken("")
+		return nil, err
+	}
+
+	return loginResp, nil
+}
+
+// UpgradeToSRP replaces the current user's password hash with an SRP verifier
+func (c *Client) UpgradeToSRP(verifier *models.SRPVerifierData) error {
+	return c.doJSON("PUT", "/api/auth/srp", verifier, nil, http.StatusNoContent)
+}
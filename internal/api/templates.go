@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// GetTemplates retrieves all templates of the authenticated user
+func (c *Client) GetTemplates() ([]*models.Template, error) {
+	var templates []*models.Template
+	if err := c.doJSON("GET", "/api/templates", nil, &templates, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// CreateTemplate creates a new template
+func (c *Client) CreateTemplate(templateReq *models.CreateTemplateRequest) (*models.Template, error) {
+	var template models.Template
+	if err := c.doJSON("POST", "/api/templates", templateReq, &template, http.StatusCreated); err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// UpdateTemplate replaces the encrypted data of a template
+func (c *Client) UpdateTemplate(templateID string, updateReq *models.UpdateTemplateRequest) (*models.Template, error) {
+	var template models.Template
+	if err := c.doJSON("PUT", "/api/templates/"+url.PathEscape(templateID), updateReq, &template, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// DeleteTemplate deletes a template
+func (c *Client) DeleteTemplate(templateID string) error {
+	return c.doJSON("DELETE", "/api/templates/"+url.PathEscape(templateID), nil, nil, http.StatusNoContent)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/config"
+)
+
+// ErrPinMismatch is returned when a server presents a certificate whose
+// public key doesn't match any key pinned for that host
+var ErrPinMismatch = errors.New("server public key does not match pinned key")
+
+// PinMismatchError describes a failed public-key pin check
+type PinMismatchError struct {
+	Host string
+	// Presented is the SPKI pin of the server's leaf certificate, useful for
+	// updating the config after a legitimate key rotation
+	Presented string
+}
+
+func (e *PinMismatchError) Error() string {
+	return fmt.Sprintf("%v for %s (server presented %s)", ErrPinMismatch, e.Host, e.Presented)
+}
+
+// Is lets errors.Is match a PinMismatchError against ErrPinMismatch
+func (e *PinMismatchError) Is(target error) bool {
+	return target == ErrPinMismatch
+}
+
+// SPKIPin returns the base64 SHA-256 hash of a certificate's public key, in
+// the format used by config.TLSConfig.PinnedKeys
+func SPKIPin(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// buildTLSConfig turns the TLS settings from the config file into a tls.Config
+func buildTLSConfig(settings config.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if settings.CAFile != "" {
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(settings.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", settings.CAFile)
+		}
+
+		tlsConfig.RootCAs = roots
+	}
+
+	if settings.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(settings.ClientCertFile, settings.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(settings.PinnedKeys) > 0 {
+		pins := make(map[string][]string, len(settings.PinnedKeys))
+		for host, hostPins := range settings.PinnedKeys {
+			pins[strings.ToLower(host)] = hostPins
+		}
+
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPins(state, pins)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyPins checks the server's verified certificate chains against the
+// pins for its host. Only certificates that actually chain to a trusted root
+// count, since a server can send any certificate alongside its own. Hosts
+// without pins only get the usual chain verification.
+func verifyPins(state tls.ConnectionState, pins map[string][]string) error {
+	host := strings.ToLower(state.ServerName)
+	hostPins, ok := pins[host]
+	if !ok {
+		return nil
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return &PinMismatchError{Host: host, Presented: "no certificate"}
+	}
+
+	for _, chain := range state.VerifiedChains {
+		for _, cert := range chain {
+			presented := SPKIPin(cert)
+			for _, pin := range hostPins {
+				if pin == presented {
+					return nil
+				}
+			}
+		}
+	}
+
+	return &PinMismatchError{Host: host, Presented: SPKIPin(state.PeerCertificates[0])}
+}
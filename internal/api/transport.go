@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// unixRequestBase is the placeholder origin used for requests sent over a
+// Unix domain socket; the host part is never resolved
+const unixRequestBase = "http://unix"
+
+// newTransport builds the HTTP transport described by the configuration
+func newTransport(cfg *config.Config) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.IsUnixSocket() {
+		socketURL, err := url.Parse(cfg.APIURL)
+		if err != nil {
+			return nil, err
+		}
+		socketPath := socketURL.Path
+
+		// Every request goes to the socket, whatever host the URL names
+		dialer := &net.Dialer{}
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+		return transport, nil
+	}
+
+	tlsConfig, err := buildTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if cfg.Proxy.URL != "" {
+		proxyFunc, err := buildProxyFunc(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = proxyFunc
+	}
+
+	return transport, nil
+}
+
+// buildProxyFunc returns a proxy selector for the configured proxy, honouring
+// the no-proxy list
+func buildProxyFunc(settings config.ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	proxyURL, err := url.Parse(settings.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transport sends these as Proxy-Authorization
+	if settings.Username != "" {
+		proxyURL.User = url.UserPassword(settings.Username, settings.Password)
+	}
+
+	proxyConfig := &httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    strings.Join(settings.NoProxy, ","),
+	}
+	selectProxy := proxyConfig.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return selectProxy(req.URL)
+	}, nil
+}
+
+// requestBase returns the origin that request URLs are built from
+func requestBase(cfg *config.Config) string {
+	if cfg.IsUnixSocket() {
+		return unixRequestBase
+	}
+	return strings.TrimRight(cfg.APIURL, "/")
+}
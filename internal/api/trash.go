@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// TrashPaste moves one of the user's pastes to the trash
+func (c *Client) TrashPaste(pasteID string) error {
+	return c.doJSON("DELETE", "/api/pastes/"+url.PathEscape(pasteID), nil, nil, http.StatusNoContent)
+}
+
+// GetTrash retrieves the user's pastes in the trash
+func (c *Client) GetTrash() ([]*models.Paste, error) {
+	var pastes []*models.Paste
+	if err := c.doJSON("GET", "/api/trash", nil, &pastes, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return pastes, nil
+}
+
+// RestorePaste moves a paste out of the trash
+func (c *Client) RestorePaste(pasteID string) error {
+	return c.doJSON("POST", "/api/trash/"+url.PathEscape(pasteID)+"/restore", nil, nil, http.StatusNoContent)
+}
+
+// PurgePaste deletes a paste in the trash for good
+func (c *Client) PurgePaste(pasteID string) error {
+	return c.doJSON("DELETE", "/api/trash/"+url.PathEscape(pasteID), nil, nil, http.StatusNoContent)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/auth"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// ErrTwoFactorRequired is matched by the error Login returns when the server
+// asks for a second factor
+var ErrTwoFactorRequired = errors.New("two-factor authentication required")
+
+// TwoFactorRequiredError carries the challenge token needed to finish a login
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+// Is lets errors.Is match a TwoFactorRequiredError against ErrTwoFactorRequired
+func (e *TwoFactorRequiredError) Is(target error) bool {
+	return target == ErrTwoFactorRequired
+}
+
+// readTwoFactorChallenge decodes a 2FA challenge from a login response
+func readTwoFactorChallenge(body io.Reader) error {
+	var challenge models.TwoFactorChallenge
+	if err := json.NewDecoder(body).Decode(&challenge); err != nil {
+		return fmt.Errorf("failed to parse two-factor challenge: %v", err)
+	}
+
+	if !challenge.TwoFactorRequired || challenge.ChallengeToken == "" {
+		return errors.New("invalid server response: missing two-factor challenge")
+	}
+
+	return &TwoFactorRequiredError{ChallengeToken: challenge.ChallengeToken}
+}
+
+// LoginTwoFactor completes a login that was answered with a 2FA challenge.
+// When the login started with SRP, srpClient checks the server proof that
+// comes with this response; it is nil for password hash logins.
+func (c *Client) LoginTwoFactor(twoFactorReq *models.TwoFactorLoginRequest, srpClient *auth.SRPClient) (*models.LoginResponse, error) {
+	reqBody, err := json.Marshal(twoFactorReq)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.endpoint("/api/auth/login/2fa"), bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	loginResp, err := c.parseLoginResponse(resp, respBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't trust a server that can't prove it holds our verifier
+	if srpClient != nil {
+		if err := srpClient.VerifyServerProof(loginResp.ServerProof); err != nil {
+			c.SetAuthToken("")
+			return nil, err
+		}
+	}
+
+	return loginResp, nil
+}
+
+// BeginTwoFactorSetup asks the server for a new TOTP secret. 2FA stays off
+// until the secret is confirmed with EnableTwoFactor.
+func (c *Client) BeginTwoFactorSetup() (*models.TwoFactorSetupResponse, error) {
+	var setup models.TwoFactorSetupResponse
+	if err := c.doJSON("POST", "/api/auth/2fa/setup", nil, &setup, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return &setup, nil
+}
+
+// EnableTwoFactor confirms the pending TOTP secret with a current code and
+// returns the one-time recovery codes
+func (c *Client) EnableTwoFactor(code string) ([]string, error) {
+	var enabled models.TwoFactorEnableResponse
+	err := c.doJSON("POST", "/api/auth/2fa/enable", &models.TwoFactorCodeRequest{Code: code}, &enabled, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	return enabled.RecoveryCodes, nil
+}
+
+// DisableTwoFactor turns 2FA off, confirmed by a TOTP or recovery code
+func (c *Client) DisableTwoFactor(confirm *models.TwoFactorCodeRequest) error {
+	return c.doJSON("POST", "/api/auth/2fa/disable", confirm, nil, http.StatusNoContent)
+}
@@ -0,0 +1,232 @@
+// Package archive reads and writes portable, passphrase encrypted backups of
+// a user's pastes
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+)
+
+// Version is the version of the archive contents written by Write
+const Version = 1
+
+// Names of the files inside an archive
+const (
+	manifestFile = "manifest.json"
+	foldersFile  = "folders.json"
+	pastesDir    = "pastes"
+)
+
+// Largest file inside an archive that is read, so a damaged archive can't
+// use up memory
+const maxEntrySize = 256 * 1024 * 1024
+
+// Manifest describes an archive and lists the pastes in it
+type Manifest struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Server     string          `json:"server"`
+	Account    string          `json:"account"`
+	Pastes     []ManifestEntry `json:"pastes"`
+}
+
+// ManifestEntry locates one paste inside an archive
+type ManifestEntry struct {
+	ID          string `json:"id"`
+	File        string `json:"file"`
+	ContentHash string `json:"content_hash"`
+}
+
+// Folder is a folder the archived pastes are filed in
+type Folder struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parent_id,omitempty"`
+	Name     string `json:"name"`
+}
+
+// Paste is a decrypted paste with its metadata
+type Paste struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Content        string    `json:"content"`
+	Language       string    `json:"language,omitempty"`
+	Format         string    `json:"format,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
+	FolderID       string    `json:"folder_id,omitempty"`
+	IsPublic       bool      `json:"is_public"`
+	Signed         bool      `json:"signed"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at,omitempty"`
+	MaxAccessCount int       `json:"max_access_count,omitempty"`
+}
+
+// Archive is the full contents of an archive
+type Archive struct {
+	Manifest Manifest
+	Folders  []Folder
+	Pastes   []*Paste
+}
+
+// ContentHash identifies paste content, so the same content is only
+// imported once
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Write encrypts the archive with passphrase and writes it to w. The
+// manifest's paste list is filled in from the pastes.
+func Write(w io.Writer, a *Archive, passphrase string) error {
+	a.Manifest.Version = Version
+	a.Manifest.Pastes = make([]ManifestEntry, 0, len(a.Pastes))
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for i, paste := range a.Pastes {
+		entry := ManifestEntry{
+			ID:          paste.ID,
+			File:        fmt.Sprintf("%s/%06d.json", pastesDir, i+1),
+			ContentHash: ContentHash(paste.Content),
+		}
+		if err := writeJSON(tw, entry.File, paste, a.Manifest.ExportedAt); err != nil {
+			return err
+		}
+		a.Manifest.Pastes = append(a.Manifest.Pastes, entry)
+	}
+
+	folders := a.Folders
+	if folders == nil {
+		folders = []Folder{}
+	}
+	if err := writeJSON(tw, foldersFile, folders, a.Manifest.ExportedAt); err != nil {
+		return err
+	}
+	if err := writeJSON(tw, manifestFile, a.Manifest, a.Manifest.ExportedAt); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	return crypto.SealArchive(w, buf.Bytes(), passphrase)
+}
+
+// writeJSON adds a JSON file to the tar stream
+func writeJSON(tw *tar.Writer, name string, v interface{}, modTime time.Time) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// Read decrypts an archive with passphrase and checks every paste listed in
+// its manifest is present and matches its content hash
+func Read(r io.Reader, passphrase string) (*Archive, error) {
+	data, err := crypto.OpenArchive(r, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("damaged archive: %v", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("damaged archive: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(tr, maxEntrySize+1))
+		if err != nil {
+			return nil, fmt.Errorf("damaged archive: %v", err)
+		}
+		if len(content) > maxEntrySize {
+			return nil, fmt.Errorf("archive file %s is too large", header.Name)
+		}
+		files[path.Clean(header.Name)] = content
+	}
+
+	a := &Archive{}
+	if err := readJSON(files, manifestFile, &a.Manifest); err != nil {
+		return nil, err
+	}
+	if a.Manifest.Version < 1 || a.Manifest.Version > Version {
+		return nil, fmt.Errorf("unsupported archive contents version %d", a.Manifest.Version)
+	}
+
+	if _, ok := files[foldersFile]; ok {
+		if err := readJSON(files, foldersFile, &a.Folders); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range a.Manifest.Pastes {
+		if !strings.HasPrefix(path.Clean(entry.File), pastesDir+"/") {
+			return nil, fmt.Errorf("archive lists paste %s outside %s", entry.ID, pastesDir)
+		}
+
+		paste := &Paste{}
+		if err := readJSON(files, entry.File, paste); err != nil {
+			return nil, err
+		}
+		if ContentHash(paste.Content) != entry.ContentHash {
+			return nil, fmt.Errorf("paste %s doesn't match its content hash", entry.ID)
+		}
+		a.Pastes = append(a.Pastes, paste)
+	}
+
+	return a, nil
+}
+
+// readJSON decodes a file of the archive
+func readJSON(files map[string][]byte, name string, v interface{}) error {
+	data, ok := files[path.Clean(name)]
+	if !ok {
+		return errors.New("archive is missing " + name)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("archive file %s is invalid: %v", name, err)
+	}
+	return nil
+}
@@ -11,7 +11,8 @@ import (
 )
 
 // HashPasswordForServer creates a hash that can be sent to the server for authentication
-// This hash doesn't expose the actual master password
+// This hash doesn't expose the actual master password, but it is a replayable
+// password equivalent. It is only used with servers that don't support SRP.
 func HashPasswordForServer(password, email string) (string, error) {
 	// Use a different salt derivation than the master key
 	salt := fmt.Sprintf("pastepal-auth:%s", email)
@@ -26,37 +27,156 @@ func HashPasswordForServer(password, email string) (string, error) {
 	return base64.StdEncoding.EncodeToString(authHash), nil
 }
 
-// RegisterUser prepares user registration data for the server
-func RegisterUser(email, password string) (*models.RegistrationData, error) {
+// RegistrationOptions selects the optional parts of a registration
+type RegistrationOptions struct {
+	// WithRecoveryKey also creates a recovery key
+	WithRecoveryKey bool
+	// UseSRP registers an SRP verifier instead of a password hash
+	UseSRP bool
+}
+
+// RegisterUser prepares user registration data for the server. With a
+// recovery key it also returns the recovery key mnemonic, which must be shown
+// to the user once and never stored.
+func RegisterUser(email, password string, opts RegistrationOptions) (*models.RegistrationData, string, error) {
 	// Generate master key from password and email
 	masterKey, err := crypto.DeriveKeyFromPassword(password, email)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Generate a random symmetric key for file encryption
 	symmetricKey, err := crypto.GenerateSymmetricKey()
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Encrypt the symmetric key with the master key
 	encryptedSymmetricKey, err := crypto.EncryptSymmetricKey(symmetricKey, masterKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Generate the identity used to receive shared pastes
+	keyPair, err := CreateKeyPair(symmetricKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	regData := &models.RegistrationData{
+		Email:                email,
+		EncryptedSymmetricKey: encryptedSymmetricKey,
+		KeyPair:              keyPair,
+	}
+
+	if opts.UseSRP {
+		regData.SRP, err = NewSRPVerifier(email, password)
+	} else {
+		// Hash the password for server authentication
+		regData.PasswordHash, err = HashPasswordForServer(password, email)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var mnemonic string
+	if opts.WithRecoveryKey {
+		regData.Recovery, mnemonic, err = CreateRecoveryKey(symmetricKey)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	return regData, mnemonic, nil
+}
+
+// CreateRecoveryKey generates a new recovery key and wraps the symmetric key
+// with it. It returns the data for the server and the mnemonic for the user.
+func CreateRecoveryKey(symmetricKey []byte) (*models.RecoveryKeyData, string, error) {
+	recoveryKey, err := crypto.GenerateRecoveryKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	wrappingKey, err := crypto.DeriveRecoveryWrappingKey(recoveryKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	recoveryEncryptedKey, err := crypto.EncryptSymmetricKey(symmetricKey, wrappingKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	authHash, err := crypto.DeriveRecoveryAuthHash(recoveryKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &models.RecoveryKeyData{
+		RecoveryAuthHash:              authHash,
+		RecoveryEncryptedSymmetricKey: recoveryEncryptedKey,
+	}, crypto.EncodeRecoveryMnemonic(recoveryKey), nil
+}
+
+// PrepareRecoveryRequest parses a recovery key mnemonic and builds the request
+// that proves possession of it to the server
+func PrepareRecoveryRequest(email, mnemonic string) (*models.RecoveryRequest, []byte, error) {
+	recoveryKey, err := crypto.DecodeRecoveryMnemonic(mnemonic)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authHash, err := crypto.DeriveRecoveryAuthHash(recoveryKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &models.RecoveryRequest{
+		Email:            email,
+		RecoveryAuthHash: authHash,
+	}, recoveryKey, nil
+}
+
+// ResetPasswordWithRecoveryKey unwraps the symmetric key with the recovery key
+// and rewraps it under a master key derived from the new password, so all
+// existing pastes stay readable
+func ResetPasswordWithRecoveryKey(email, newPassword string, recoveryKey []byte, recoveryResp *models.RecoveryResponse, useSRP bool) (*models.RecoveryResetRequest, error) {
+	wrappingKey, err := crypto.DeriveRecoveryWrappingKey(recoveryKey)
 	if err != nil {
 		return nil, err
 	}
 
-	// Hash the password for server authentication
-	passwordHash, err := HashPasswordForServer(password, email)
+	symmetricKey, err := crypto.DecryptSymmetricKey(recoveryResp.RecoveryEncryptedSymmetricKey, wrappingKey)
+	if err != nil {
+		return nil, errors.New("recovery key does not match this account")
+	}
+
+	masterKey, err := crypto.DeriveKeyFromPassword(newPassword, email)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.RegistrationData{
-		Email:                email,
-		PasswordHash:         passwordHash,
+	encryptedSymmetricKey, err := crypto.EncryptSymmetricKey(symmetricKey, masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	resetReq := &models.RecoveryResetRequest{
+		RecoveryToken:         recoveryResp.RecoveryToken,
 		EncryptedSymmetricKey: encryptedSymmetricKey,
-	}, nil
+	}
+
+	if useSRP {
+		resetReq.SRP, err = NewSRPVerifier(email, newPassword)
+	} else {
+		resetReq.PasswordHash, err = HashPasswordForServer(newPassword, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resetReq, nil
 }
 
 // LoginUser authenticates a user and returns the decrypted symmetric key
@@ -66,8 +186,14 @@ func LoginUser(email, password string, encryptedSymmetricKey string) ([]byte, er
 	if err != nil {
 		return nil, err
 	}
+	defer clear(masterKey)
+
+	return UnlockSymmetricKey(masterKey, encryptedSymmetricKey)
+}
 
-	// Decrypt the symmetric key
+// UnlockSymmetricKey decrypts the symmetric key with a master key that was
+// already derived from the password
+func UnlockSymmetricKey(masterKey []byte, encryptedSymmetricKey string) ([]byte, error) {
 	symmetricKey, err := crypto.DecryptSymmetricKey(encryptedSymmetricKey, masterKey)
 	if err != nil {
 		return nil, errors.New("invalid credentials or corrupted key")
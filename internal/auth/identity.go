@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// CreateKeyPair generates a sharing and signing identity and wraps its
+// private keys with the symmetric key
+func CreateKeyPair(symmetricKey []byte) (*models.KeyPairData, error) {
+	privateKey, publicKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedPrivateKey, err := crypto.EncryptSymmetricKey(privateKey, symmetricKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPair := &models.KeyPairData{
+		PublicKey:           base64.StdEncoding.EncodeToString(publicKey),
+		EncryptedPrivateKey: encryptedPrivateKey,
+	}
+
+	if err := AddSigningKey(keyPair, symmetricKey); err != nil {
+		return nil, err
+	}
+
+	return keyPair, nil
+}
+
+// AddSigningKey generates a signing key for an identity that predates signed
+// pastes
+func AddSigningKey(keyPair *models.KeyPairData, symmetricKey []byte) error {
+	privateKey, publicKey, err := crypto.GenerateSigningKey()
+	if err != nil {
+		return err
+	}
+
+	encryptedSigningKey, err := crypto.EncryptSymmetricKey(privateKey, symmetricKey)
+	if err != nil {
+		return err
+	}
+
+	keyPair.SigningPublicKey = base64.StdEncoding.EncodeToString(publicKey)
+	keyPair.EncryptedSigningKey = encryptedSigningKey
+	return nil
+}
+
+// UnlockPrivateKey decrypts the private key of a sharing identity
+func UnlockPrivateKey(keyPair *models.KeyPairData, symmetricKey []byte) ([]byte, error) {
+	privateKey, err := crypto.DecryptSymmetricKey(keyPair.EncryptedPrivateKey, symmetricKey)
+	if err != nil {
+		return nil, errors.New("failed to unlock sharing key")
+	}
+	return privateKey, nil
+}
+
+// UnlockSigningKey decrypts the signing key of an identity
+func UnlockSigningKey(keyPair *models.KeyPairData, symmetricKey []byte) ([]byte, error) {
+	signingKey, err := crypto.DecryptSymmetricKey(keyPair.EncryptedSigningKey, symmetricKey)
+	if err != nil {
+		return nil, errors.New("failed to unlock signing key")
+	}
+	return signingKey, nil
+}
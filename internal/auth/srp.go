@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// SRP-6a (RFC 5054) with the 2048-bit group and SHA-256. The server stores
+// only a salt and verifier, and neither the wire exchange nor a leaked server
+// database contains anything that can be replayed to log in.
+
+// ProtocolSRP and ProtocolPasswordHash name the login protocols a server can
+// advertise
+const (
+	ProtocolSRP          = "srp6a-sha256"
+	ProtocolPasswordHash = "password-hash"
+)
+
+// srpGroupN is the 2048-bit safe prime from RFC 5054 appendix A
+var srpGroupN, _ = new(big.Int).SetString(strings.Join(strings.Fields(`
+	AC6BDB41 324A9A9B F166DE5E 1389582F AF72B665 1987EE07 FC319294 3DB56050
+	A37329CB B4A099ED 8193E075 7767A13D D52312AB 4B03310D CD7F48A9 DA04FD50
+	E8083969 EDB767B0 CF609517 9A163AB3 661A05FB D5FAAAE8 2918A996 2F0B93B8
+	55F97993 EC975EEA A80D740A DBF4FF74 7359D041 D5C33EA7 1D281E44 6B14773B
+	CA97B43A 23FB8016 76BD207A 436C6481 F1D2B907 8717461A 5B9D32E6 88F87748
+	544523B5 24B0D57D 5EA77A27 75D2ECFA 032CFBDB F52FB378 61602790 04E57AE6
+	AF874E73 03CE5329 9CCC041C 7BC308D8 2A5698F3 A8D0C382 71AE35F8 E9DBFBB6
+	94B5C803 D89F7AE4 35DE236D 525F5475 9B65E372 FCD68EF2 0FA7111F 9E4AFF73`), ""), 16)
+
+// srpGroupG is the generator for srpGroupN
+var srpGroupG = big.NewInt(2)
+
+// srpSaltSize is the size of the per-user SRP salt in bytes
+const srpSaltSize = 32
+
+// SRPClient runs the client side of one SRP-6a login
+type SRPClient struct {
+	email       string
+	stretched   []byte
+	a           *big.Int
+	publicA     *big.Int
+	premaster   []byte
+	clientProof []byte
+}
+
+// NewSRPVerifier creates the salt and verifier a server stores instead of a
+// password hash
+func NewSRPVerifier(email, password string) (*models.SRPVerifierData, error) {
+	salt := make([]byte, srpSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	x := srpPrivateKey(salt, email, stretchPasswordForSRP(password, email))
+	verifier := new(big.Int).Exp(srpGroupG, x, srpGroupN)
+
+	return &models.SRPVerifierData{
+		Salt:     base64.StdEncoding.EncodeToString(salt),
+		Verifier: base64.StdEncoding.EncodeToString(srpPad(verifier)),
+	}, nil
+}
+
+// NewSRPClient starts an SRP login and generates the client's ephemeral key
+func NewSRPClient(email, password string) (*SRPClient, error) {
+	// The private ephemeral must be non-zero; 256 random bits make a zero
+	// value practically impossible, but check anyway
+	a, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 256))
+	if err != nil {
+		return nil, err
+	}
+	if a.Sign() == 0 {
+		return nil, errors.New("failed to generate SRP ephemeral key")
+	}
+
+	return &SRPClient{
+		email:     email,
+		stretched: stretchPasswordForSRP(password, email),
+		a:         a,
+		publicA:   new(big.Int).Exp(srpGroupG, a, srpGroupN),
+	}, nil
+}
+
+// PublicKey returns the client's public ephemeral value A
+func (c *SRPClient) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(srpPad(c.publicA))
+}
+
+// ProcessChallenge takes the salt and server ephemeral B and returns the
+// client proof M1
+func (c *SRPClient) ProcessChallenge(saltBase64, publicBBase64 string) (string, error) {
+	salt, err := base64.StdEncoding.DecodeString(saltBase64)
+	if err != nil {
+		return "", fmt.Errorf("invalid SRP salt: %v", err)
+	}
+
+	publicBBytes, err := base64.StdEncoding.DecodeString(publicBBase64)
+	if err != nil {
+		return "", fmt.Errorf("invalid SRP server key: %v", err)
+	}
+	publicB := new(big.Int).SetBytes(publicBBytes)
+
+	// A malicious server could force a known session key with B = 0 mod N
+	if new(big.Int).Mod(publicB, srpGroupN).Sign() == 0 {
+		return "", errors.New("invalid SRP server key")
+	}
+
+	u := new(big.Int).SetBytes(srpHash(srpPad(c.publicA), srpPad(publicB)))
+	if u.Sign() == 0 {
+		return "", errors.New("invalid SRP scrambling parameter")
+	}
+
+	k := new(big.Int).SetBytes(srpHash(srpPad(srpGroupN), srpPad(srpGroupG)))
+	x := srpPrivateKey(salt, c.email, c.stretched)
+
+	// S = (B - k * g^x) ^ (a + u * x) mod N
+	base := new(big.Int).Exp(srpGroupG, x, srpGroupN)
+	base.Mul(base, k)
+	base.Sub(publicB, base)
+	base.Mod(base, srpGroupN)
+
+	exponent := new(big.Int).Mul(u, x)
+	exponent.Add(exponent, c.a)
+
+	premaster := new(big.Int).Exp(base, exponent, srpGroupN)
+	c.premaster = srpPad(premaster)
+
+	// M1 = H(PAD(A) | PAD(B) | PAD(S))
+	c.clientProof = srpHash(srpPad(c.publicA), srpPad(publicB), c.premaster)
+
+	return base64.StdEncoding.EncodeToString(c.clientProof), nil
+}
+
+// ErrServerProof is returned when the server fails to prove it knows the
+// password verifier
+var ErrServerProof = errors.New("server failed to prove knowledge of the password verifier")
+
+// VerifyServerProof checks the server proof M2, which shows the server knows
+// the verifier and isn't an impostor
+func (c *SRPClient) VerifyServerProof(serverProofBase64 string) error {
+	if c.clientProof == nil {
+		return errors.New("SRP challenge has not been processed")
+	}
+
+	serverProof, err := base64.StdEncoding.DecodeString(serverProofBase64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid proof: %v", ErrServerProof, err)
+	}
+
+	// M2 = H(PAD(A) | M1 | PAD(S))
+	expected := srpHash(srpPad(c.publicA), c.clientProof, c.premaster)
+	if subtle.ConstantTimeCompare(expected, serverProof) != 1 {
+		return ErrServerProof
+	}
+
+	return nil
+}
+
+// stretchPasswordForSRP slows down offline guessing against a leaked verifier
+func stretchPasswordForSRP(password, email string) []byte {
+	return pbkdf2.Key(
+		[]byte(password),
+		[]byte(fmt.Sprintf("pastepal-srp:%s", email)),
+		100000,
+		32,
+		sha256.New,
+	)
+}
+
+// srpPrivateKey computes x = H(salt | H(email | ":" | stretched password))
+func srpPrivateKey(salt []byte, email string, stretched []byte) *big.Int {
+	inner := srpHash([]byte(email), []byte(":"), stretched)
+	return new(big.Int).SetBytes(srpHash(salt, inner))
+}
+
+// srpHash hashes the concatenation of its arguments
+func srpHash(parts ...[]byte) []byte {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+	}
+	return hash.Sum(nil)
+}
+
+// srpPad left-pads a group element to the byte length of N
+func srpPad(value *big.Int) []byte {
+	padded := make([]byte, (srpGroupN.BitLen()+7)/8)
+	return value.FillBytes(padded)
+}
@@ -1,16 +1,74 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 )
 
+// Supported UI themes
+const (
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+)
+
 // Config represents the application configuration
 type Config struct {
-	APIURL      string `json:"api_url"`
-	StoragePath string `json:"storage_path"`
-	DebugMode   bool   `json:"debug_mode"`
+	APIURL         string `json:"api_url"`
+	StoragePath    string `json:"storage_path"`
+	DebugMode      bool   `json:"debug_mode"`
+	RequestTimeout int    `json:"request_timeout_seconds"`
+	Theme          string `json:"theme"`
+	CacheMaxPastes int    `json:"cache_max_pastes"` // 0 means unlimited
+	// AutoLockMinutes locks the vault after this long without activity; 0
+	// disables it
+	AutoLockMinutes  int  `json:"auto_lock_minutes"`
+	LockOnScreenLock bool `json:"lock_on_screen_lock"`
+	// A quick unlock PIN is deleted after this many wrong attempts, or this
+	// many hours after it was set up
+	PINMaxAttempts   int `json:"pin_max_attempts"`
+	PINLifetimeHours int `json:"pin_lifetime_hours"`
+	// ClipboardClearSeconds clears the clipboard this long after paste
+	// content or a share link was copied to it; 0 disables it
+	ClipboardClearSeconds int `json:"clipboard_clear_seconds"`
+	// SystemTray keeps PastePal in the system tray, where closing the
+	// window hides it instead of quitting
+	SystemTray bool `json:"system_tray"`
+	// QuickCaptureExpiryHours is how long pastes made with quick capture
+	// stay available
+	QuickCaptureExpiryHours int         `json:"quick_capture_expiry_hours"`
+	TLS                     TLSConfig   `json:"tls"`
+	Proxy                   ProxyConfig `json:"proxy"`
+}
+
+// ProxyConfig holds the HTTP proxy used to reach the server
+type ProxyConfig struct {
+	// URL of the proxy, e.g. http://proxy.corp:3128. When empty the standard
+	// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables are used.
+	URL string `json:"url,omitempty"`
+	// Username and Password authenticate against the proxy. They override any
+	// credentials embedded in URL.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// NoProxy lists hosts, domains (".corp.example") or CIDR ranges that are
+	// reached directly
+	NoProxy []string `json:"no_proxy,omitempty"`
+}
+
+// TLSConfig holds the TLS settings used when talking to the server
+type TLSConfig struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile string `json:"ca_file,omitempty"`
+	// ClientCertFile and ClientKeyFile enable mutual TLS when both are set
+	ClientCertFile string `json:"client_cert_file,omitempty"`
+	ClientKeyFile  string `json:"client_key_file,omitempty"`
+	// PinnedKeys maps a server host name to the base64 SHA-256 hashes of the
+	// SubjectPublicKeyInfo it is allowed to present
+	PinnedKeys map[string][]string `json:"pinned_keys,omitempty"`
 }
 
 // DefaultConfig returns the default configuration
@@ -23,14 +81,218 @@ func DefaultConfig() *Config {
 
 	return &Config{
 		// APIURL:      "https://api.pastepal.com",
-		APIURL:      "http://localhost:8080",
-		StoragePath: filepath.Join(homeDir, ".pastepal"),
-		DebugMode:   false,
+		APIURL:                  "http://localhost:8080",
+		StoragePath:             filepath.Join(homeDir, ".pastepal"),
+		DebugMode:               false,
+		RequestTimeout:          10,
+		Theme:                   ThemeDark,
+		CacheMaxPastes:          500,
+		AutoLockMinutes:         15,
+		LockOnScreenLock:        true,
+		PINMaxAttempts:          5,
+		PINLifetimeHours:        72,
+		ClipboardClearSeconds:   30,
+		SystemTray:              true,
+		QuickCaptureExpiryHours: 24,
+	}
+}
+
+// setting checks one part of the configuration and can put back its
+// default when the check fails
+type setting struct {
+	validate func(c *Config) error
+	reset    func(c, defaults *Config)
+}
+
+// settings lists the checks Validate runs, in order
+var settings = []setting{
+	{(*Config).validateAPIURL, func(c, d *Config) { c.APIURL = d.APIURL }},
+	{func(c *Config) error {
+		if c.StoragePath == "" {
+			return errors.New("storage_path must not be empty")
+		}
+		return nil
+	}, func(c, d *Config) { c.StoragePath = d.StoragePath }},
+	{func(c *Config) error {
+		if c.RequestTimeout <= 0 {
+			return errors.New("request_timeout_seconds must be greater than zero")
+		}
+		return nil
+	}, func(c, d *Config) { c.RequestTimeout = d.RequestTimeout }},
+	{func(c *Config) error {
+		if c.Theme != ThemeDark && c.Theme != ThemeLight {
+			return fmt.Errorf("invalid theme %q: expected %q or %q", c.Theme, ThemeDark, ThemeLight)
+		}
+		return nil
+	}, func(c, d *Config) { c.Theme = d.Theme }},
+	{func(c *Config) error {
+		if c.CacheMaxPastes < 0 {
+			return errors.New("cache_max_pastes must not be negative")
+		}
+		return nil
+	}, func(c, d *Config) { c.CacheMaxPastes = d.CacheMaxPastes }},
+	{func(c *Config) error {
+		if c.AutoLockMinutes < 0 {
+			return errors.New("auto_lock_minutes must not be negative")
+		}
+		return nil
+	}, func(c, d *Config) { c.AutoLockMinutes = d.AutoLockMinutes }},
+	{func(c *Config) error {
+		if c.PINMaxAttempts <= 0 {
+			return errors.New("pin_max_attempts must be greater than zero")
+		}
+		return nil
+	}, func(c, d *Config) { c.PINMaxAttempts = d.PINMaxAttempts }},
+	{func(c *Config) error {
+		if c.PINLifetimeHours <= 0 {
+			return errors.New("pin_lifetime_hours must be greater than zero")
+		}
+		return nil
+	}, func(c, d *Config) { c.PINLifetimeHours = d.PINLifetimeHours }},
+	{func(c *Config) error {
+		if c.ClipboardClearSeconds < 0 {
+			return errors.New("clipboard_clear_seconds must not be negative")
+		}
+		return nil
+	}, func(c, d *Config) { c.ClipboardClearSeconds = d.ClipboardClearSeconds }},
+	{func(c *Config) error {
+		if c.QuickCaptureExpiryHours <= 0 {
+			return errors.New("quick_capture_expiry_hours must be greater than zero")
+		}
+		return nil
+	}, func(c, d *Config) { c.QuickCaptureExpiryHours = d.QuickCaptureExpiryHours }},
+	{func(c *Config) error { return c.TLS.Validate() }, func(c, d *Config) { c.TLS = d.TLS }},
+	{func(c *Config) error { return c.Proxy.Validate() }, func(c, d *Config) { c.Proxy = d.Proxy }},
+}
+
+// Validate checks that the configuration values are usable
+func (c *Config) Validate() error {
+	for _, s := range settings {
+		if err := s.validate(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetInvalid puts back the default of every setting that fails
+// validation, and returns why each of them failed
+func (c *Config) resetInvalid() []error {
+	defaults := DefaultConfig()
+
+	var problems []error
+	for _, s := range settings {
+		if err := s.validate(c); err != nil {
+			s.reset(c, defaults)
+			problems = append(problems, err)
+		}
+	}
+	return problems
+}
+
+// validateAPIURL checks that the server URL has a supported scheme
+func (c *Config) validateAPIURL() error {
+	apiURL, err := url.Parse(c.APIURL)
+	if err != nil {
+		return fmt.Errorf("invalid api_url: %v", err)
+	}
+	switch apiURL.Scheme {
+	case "http", "https":
+		if apiURL.Host == "" {
+			return errors.New("invalid api_url: missing host")
+		}
+	case "unix":
+		if apiURL.Path == "" {
+			return errors.New("invalid api_url: missing socket path")
+		}
+	default:
+		return fmt.Errorf("invalid api_url: unsupported scheme %q", apiURL.Scheme)
+	}
+	return nil
+}
+
+// IsUnixSocket reports whether the API is reached over a Unix domain socket
+func (c *Config) IsUnixSocket() bool {
+	apiURL, err := url.Parse(c.APIURL)
+	return err == nil && apiURL.Scheme == "unix"
+}
+
+// Validate checks that the proxy settings are usable
+func (p *ProxyConfig) Validate() error {
+	if p.URL == "" {
+		if p.Username != "" || p.Password != "" || len(p.NoProxy) > 0 {
+			return errors.New("proxy: url is required when proxy options are set")
+		}
+		return nil
 	}
+
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return fmt.Errorf("proxy: invalid url: %v", err)
+	}
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5" {
+		return fmt.Errorf("proxy: unsupported scheme %q", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return errors.New("proxy: url is missing a host")
+	}
+	if p.Password != "" && p.Username == "" {
+		return errors.New("proxy: password set without username")
+	}
+
+	return nil
+}
+
+// Validate checks that the TLS settings are consistent
+func (t *TLSConfig) Validate() error {
+	if (t.ClientCertFile == "") != (t.ClientKeyFile == "") {
+		return errors.New("tls: client_cert_file and client_key_file must be set together")
+	}
+
+	for host, pins := range t.PinnedKeys {
+		if len(pins) == 0 {
+			return fmt.Errorf("tls: no pins listed for %q", host)
+		}
+		for _, pin := range pins {
+			hash, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(hash) != 32 {
+				return fmt.Errorf("tls: invalid pin %q for %q: expected a base64 SHA-256 hash", pin, host)
+			}
+		}
+	}
+
+	return nil
 }
 
 // LoadConfig loads the configuration from a file
 func LoadConfig(path string) (*Config, error) {
+	config, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadConfigOrDefaults loads the configuration from a file like LoadConfig,
+// but settings that fail validation get their defaults instead of failing,
+// so a file written by an older version still starts the app. It returns
+// why each reset setting was invalid.
+func LoadConfigOrDefaults(path string) (*Config, []error, error) {
+	config, err := readConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return config, config.resetInvalid(), nil
+}
+
+// readConfig reads the configuration from a file without validating it
+func readConfig(path string) (*Config, error) {
 	// If the file doesn't exist, return default config
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		config := DefaultConfig()
@@ -42,12 +304,13 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	// Start from the defaults so fields missing from older files keep sane values
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
 		return nil, err
 	}
 
-	return &config, nil
+	return config, nil
 }
 
 // SaveConfig saves the configuration to a file
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay coalesces the burst of events editors emit when saving a file
+const reloadDelay = 250 * time.Millisecond
+
+// Watcher watches a config file and reloads it when it changes on disk
+type Watcher struct {
+	path     string
+	watcher  *fsnotify.Watcher
+	onChange func(*Config)
+	onError  func(error)
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewWatcher starts watching the config file at path. onChange receives every
+// successfully loaded and validated config, onError receives load failures.
+func NewWatcher(path string, onChange func(*Config), onError func(error)) (*Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// Watch the directory rather than the file itself, since many editors save
+	// by writing a temporary file and renaming it over the original
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+	if err := fsWatcher.Add(dir); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		path:     filepath.Clean(path),
+		watcher:  fsWatcher,
+		onChange: onChange,
+		onError:  onError,
+		done:     make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+// Close stops watching the config file
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.watcher.Close()
+	})
+	return err
+}
+
+// run processes file system events until the watcher is closed
+func (w *Watcher) run() {
+	var timer *time.Timer
+	reload := make(chan struct{}, 1)
+
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != w.path {
+				continue
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(reloadDelay, func() {
+				select {
+				case reload <- struct{}{}:
+				default:
+				}
+			})
+
+		case <-reload:
+			w.reload()
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			if w.onError != nil {
+				w.onError(err)
+			}
+		}
+	}
+}
+
+// reload reads the config file and notifies the change or error callback
+func (w *Watcher) reload() {
+	// A rename away from the path leaves nothing to load; wait for the next write
+	if _, err := os.Stat(w.path); os.IsNotExist(err) {
+		return
+	}
+
+	cfg, err := LoadConfig(w.path)
+	if err != nil {
+		if w.onError != nil {
+			w.onError(err)
+		}
+		return
+	}
+
+	if w.onChange != nil {
+		w.onChange(cfg)
+	}
+}
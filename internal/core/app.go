@@ -6,13 +6,17 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/JacobRWebb/PastePal-OS/internal/api"
 	"github.com/JacobRWebb/PastePal-OS/internal/auth"
 	"github.com/JacobRWebb/PastePal-OS/internal/config"
 	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
 	"github.com/JacobRWebb/PastePal-OS/internal/models"
+	"github.com/JacobRWebb/PastePal-OS/internal/search"
 	"github.com/JacobRWebb/PastePal-OS/internal/storage"
+	"github.com/JacobRWebb/PastePal-OS/internal/syntax"
 )
 
 // PastePalApp represents the main application
@@ -21,54 +25,113 @@ type PastePalApp struct {
 	APIClient     *api.Client
 	LocalStorage  *storage.LocalStorage
 	CurrentUser   *models.User
-	SymmetricKey  []byte // In-memory only, never persisted to disk
 	IsLoggedIn    bool
 	mutex         sync.RWMutex
+
+	// Key material, in-memory only and never persisted to disk
+	keys    KeyHolder
+	keyPair *models.KeyPairData // Encrypted, for unlocking without the server
+
+	// Vault locking
+	locked       bool
+	lastActivity atomic.Int64 // Unix nanoseconds
+	lockHandler  func()
+	stopAutoLock func()
+
+	// Purging the trash in the background while the vault is unlocked
+	purgeTrash     bool
+	stopTrashPurge func()
+
+	// Settings kept with the account, loaded at login
+	settings AccountSettings
+
+	// Decrypted search index, loaded on first use and dropped when locking
+	searchIndex *search.Index
+	searchMutex sync.Mutex
+
+	// Login waiting for a second factor
+	pendingLogin  *pendingLogin
+	pendingSecret string
+
+	// Live config reload
+	configPath      string
+	configWatcher   *config.Watcher
+	configListeners map[int]ConfigListener
+	nextListenerID  int
+	pendingAPIURL   string // Held back until logging out
+	configMutex     sync.RWMutex
 }
 
 // NewApp creates a new instance of the application
 func NewApp(configPath string) (*PastePalApp, error) {
 	// Load configuration
-	cfg, err := config.LoadConfig(configPath)
+	cfg, problems, err := config.LoadConfigOrDefaults(configPath)
 	if err != nil {
 		return nil, err
 	}
+	for _, problem := range problems {
+		fmt.Printf("[Core] Using the default for an invalid setting in %s: %v\n", configPath, problem)
+	}
 
 	// Create API client
-	apiClient := api.NewClient(cfg.APIURL)
+	apiClient, err := api.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create local storage
 	localStorage, err := storage.NewLocalStorage(cfg.StoragePath)
 	if err != nil {
 		return nil, err
 	}
+	if err := localStorage.SetCacheLimit(cfg.CacheMaxPastes); err != nil {
+		return nil, err
+	}
+
+	app := &PastePalApp{
+		Config:          cfg,
+		APIClient:       apiClient,
+		LocalStorage:    localStorage,
+		IsLoggedIn:      false,
+		settings:        DefaultAccountSettings(),
+		mutex:           sync.RWMutex{},
+		configPath:      configPath,
+		configListeners: make(map[int]ConfigListener),
+	}
+
+	// Keep the cache in step with config reloads
+	app.SubscribeConfig(app.applyConfigToServices)
 
-	return &PastePalApp{
-		Config:       cfg,
-		APIClient:    apiClient,
-		LocalStorage: localStorage,
-		IsLoggedIn:   false,
-		mutex:        sync.RWMutex{},
-	}, nil
+	return app, nil
 }
 
-// Register registers a new user
-func (app *PastePalApp) Register(email, password string) error {
+// Register registers a new user. With withRecoveryKey set it returns the
+// recovery key mnemonic, which the caller must show to the user once.
+func (app *PastePalApp) Register(email, password string, withRecoveryKey bool) (string, error) {
 	app.mutex.Lock()
 	defer app.mutex.Unlock()
 
+	// Servers that support SRP get a verifier instead of a password hash
+	useSRP, err := app.APIClient.SupportsSRP()
+	if err != nil {
+		return "", err
+	}
+
 	// Prepare registration data
-	regData, err := auth.RegisterUser(email, password)
+	regData, recoveryMnemonic, err := auth.RegisterUser(email, password, auth.RegistrationOptions{
+		WithRecoveryKey: withRecoveryKey,
+		UseSRP:          useSRP,
+	})
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Send registration to server
 	if err := app.APIClient.Register(regData); err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return recoveryMnemonic, nil
 }
 
 // Login authenticates a user and sets up their session
@@ -76,107 +139,112 @@ func (app *PastePalApp) Login(email, password string, rememberMe bool) error {
 	app.mutex.Lock()
 	defer app.mutex.Unlock()
 
-	// Prepare login request
-	loginReq, err := auth.PrepareLoginRequest(email, password)
+	useSRP, err := app.APIClient.SupportsSRP()
 	if err != nil {
 		return err
 	}
 
-	// Send login request to server
-	loginResp, err := app.APIClient.Login(loginReq)
-	if err != nil {
-		return err
-	}
+	var loginResp *models.LoginResponse
+	var srpClient *auth.SRPClient
+	upgradeToSRP := false
 
-	// Check if we have a valid user ID in the response
-	userID := loginResp.User.ID
-	if userID == "" {
-		// Fall back to the old format if needed
-		userID = loginResp.UserID
-	}
+	if useSRP {
+		srpClient, err = auth.NewSRPClient(email, password)
+		if err != nil {
+			return err
+		}
 
-	if userID == "" {
-		return errors.New("invalid login response: no user ID")
+		loginResp, err = app.APIClient.LoginSRP(email, srpClient)
+		if errors.Is(err, api.ErrSRPNotEnrolled) {
+			// Accounts created before the server supported SRP log in with
+			// their password hash once and are then upgraded
+			srpClient = nil
+			useSRP = false
+			upgradeToSRP = true
+		} else if err != nil {
+			return app.handleLoginError(err, email, password, rememberMe, srpClient, false)
+		}
 	}
 
-	// Use the auth token if provided
-	if loginResp.AuthToken != "" {
-		app.APIClient.SetAuthToken(loginResp.AuthToken)
-	}
+	if !useSRP {
+		// A server that suddenly claims an SRP account has no verifier, or
+		// doesn't speak SRP at all, would get a replayable password hash
+		if app.LocalStorage.SRPEnrolled(email) {
+			return errors.New("this account logged in with SRP before, but the server now asks for a password hash; refusing to send it")
+		}
 
-	// Check if we have an encrypted symmetric key
-	if loginResp.EncryptedSymmetricKey == "" {
-		return errors.New("invalid login response: no encrypted symmetric key")
+		// Prepare login request
+		loginReq, err := auth.PrepareLoginRequest(email, password)
+		if err != nil {
+			return err
+		}
+
+		// Send login request to server
+		loginResp, err = app.APIClient.Login(loginReq)
+		if err != nil {
+			return app.handleLoginError(err, email, password, rememberMe, nil, upgradeToSRP)
+		}
 	}
 
-	// Derive master key and decrypt symmetric key
-	symmetricKey, err := auth.LoginUser(email, password, loginResp.EncryptedSymmetricKey)
+	masterKey, srpVerifier, err := deriveLoginSecrets(email, password, upgradeToSRP)
 	if err != nil {
 		return err
 	}
+	defer clear(masterKey)
 
-	// Set user data
-	app.CurrentUser = &models.User{
-		ID:                   userID,
-		Email:                email,
-		EncryptedSymmetricKey: loginResp.EncryptedSymmetricKey,
-		CreatedAt:            loginResp.User.CreatedAt,
-	}
-
-	// Store symmetric key in memory only
-	app.SymmetricKey = symmetricKey
-	app.IsLoggedIn = true
+	return app.finishLogin(email, masterKey, rememberMe, useSRP, srpVerifier, loginResp)
+}
 
-	// Save session locally
-	err = app.LocalStorage.SaveUserSession(email, symmetricKey)
+// deriveLoginSecrets derives everything finishing a login needs from the
+// password: the master key, and the SRP verifier when the account is being
+// upgraded to SRP
+func deriveLoginSecrets(email, password string, upgradeToSRP bool) ([]byte, *models.SRPVerifierData, error) {
+	masterKey, err := crypto.DeriveKeyFromPassword(password, email)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	// Save credentials if remember me is enabled
-	if rememberMe {
-		// We're saving the password hash, not the plain password for security
-		passwordHash := loginReq.PasswordHash
-		err = app.LocalStorage.SaveCredentials(email, passwordHash, rememberMe)
-		if err != nil {
-			// Non-critical error, just log it and continue
-			// In a production app, you might want to notify the user
-			return nil
+	var srpVerifier *models.SRPVerifierData
+	if upgradeToSRP {
+		if srpVerifier, err = auth.NewSRPVerifier(email, password); err != nil {
+			fmt.Printf("[Core] Failed to prepare SRP upgrade: %v\n", err)
 		}
 	}
 
-	return nil
+	return masterKey, srpVerifier, nil
 }
 
-// AutoLogin attempts to log in using saved credentials
-func (app *PastePalApp) AutoLogin() bool {
-	app.mutex.Lock()
-	defer app.mutex.Unlock()
-
-	// Check if we already have an active session
-	if app.IsLoggedIn {
-		return true
-	}
-
-	// Try to get saved credentials
-	email, passwordHash, err := app.LocalStorage.GetSavedCredentials()
-	if err != nil {
-		// No saved credentials or error reading them
-		return false
+// handleLoginError remembers a login that needs a second factor so
+// CompleteTwoFactorLogin can finish it, keeping the derived master key
+// rather than the password. srpClient is the client of an SRP login, whose
+// server proof comes with the second step. Callers must hold the write lock.
+func (app *PastePalApp) handleLoginError(err error, email, password string, rememberMe bool, srpClient *auth.SRPClient, upgradeToSRP bool) error {
+	var challenge *api.TwoFactorRequiredError
+	if !errors.As(err, &challenge) {
+		return err
 	}
 
-	// Create a login request with the saved credentials
-	loginReq := &models.LoginRequest{
-		Email:        email,
-		PasswordHash: passwordHash,
+	masterKey, srpVerifier, deriveErr := deriveLoginSecrets(email, password, upgradeToSRP)
+	if deriveErr != nil {
+		return deriveErr
 	}
 
-	// Send login request to server
-	loginResp, err := app.APIClient.Login(loginReq)
-	if err != nil {
-		return false
+	app.dropPendingLogin()
+	app.pendingLogin = &pendingLogin{
+		email:          email,
+		rememberMe:     rememberMe,
+		srpClient:      srpClient,
+		srpVerifier:    srpVerifier,
+		challengeToken: challenge.ChallengeToken,
 	}
+	app.pendingLogin.keys.SetMasterKey(masterKey)
+	return err
+}
 
+// finishLogin unlocks the account from a successful login response.
+// srpVerifier replaces the account's password hash on the server when set.
+// Callers must hold the write lock.
+func (app *PastePalApp) finishLogin(email string, masterKey []byte, rememberMe, usedSRP bool, srpVerifier *models.SRPVerifierData, loginResp *models.LoginResponse) error {
 	// Check if we have a valid user ID in the response
 	userID := loginResp.User.ID
 	if userID == "" {
@@ -185,7 +253,7 @@ func (app *PastePalApp) AutoLogin() bool {
 	}
 
 	if userID == "" {
-		return false
+		return errors.New("invalid login response: no user ID")
 	}
 
 	// Use the auth token if provided
@@ -193,11 +261,16 @@ func (app *PastePalApp) AutoLogin() bool {
 		app.APIClient.SetAuthToken(loginResp.AuthToken)
 	}
 
-	// For auto-login, we don't have the original password to decrypt the symmetric key
-	// Instead, we'll use the saved credentials directly
-	// In a real app, you might want to encrypt the symmetric key with a device-specific key
-	// But for this example, we'll just use the password hash as the symmetric key
-	symmetricKey := []byte(passwordHash)
+	// Check if we have an encrypted symmetric key
+	if loginResp.EncryptedSymmetricKey == "" {
+		return errors.New("invalid login response: no encrypted symmetric key")
+	}
+
+	// Decrypt the symmetric key with the master key
+	symmetricKey, err := auth.UnlockSymmetricKey(masterKey, loginResp.EncryptedSymmetricKey)
+	if err != nil {
+		return err
+	}
 
 	// Set user data
 	app.CurrentUser = &models.User{
@@ -205,61 +278,221 @@ func (app *PastePalApp) AutoLogin() bool {
 		Email:                email,
 		EncryptedSymmetricKey: loginResp.EncryptedSymmetricKey,
 		CreatedAt:            loginResp.User.CreatedAt,
+		TwoFactorEnabled:     loginResp.User.TwoFactorEnabled,
+		HasRecoveryKey:       loginResp.User.HasRecoveryKey,
 	}
 
 	// Store symmetric key in memory only
-	app.SymmetricKey = symmetricKey
+	app.keys.SetSymmetricKey(symmetricKey)
 	app.IsLoggedIn = true
+	app.locked = false
+	app.RecordActivity()
+
+	// Settings another device saved apply here too
+	app.settings = DefaultAccountSettings()
+	if err := app.loadAccountSettings(); err != nil {
+		fmt.Printf("[Core] Using default account settings: %v\n", err)
+	}
+
+	// Unlock the sharing identity, creating one for accounts that predate it
+	if err := app.loadKeyPair(loginResp.KeyPair); err != nil {
+		fmt.Printf("[Core] Sharing is unavailable: %v\n", err)
+	}
+
+	app.startTrashPurge()
 
 	// Save session locally
-	err = app.LocalStorage.SaveUserSession(email, symmetricKey)
+	err = app.LocalStorage.SaveUserSession(email, app.keys.SymmetricKey())
 	if err != nil {
-		return false
+		return err
 	}
 
-	return true
+	// Replace the replayable password hash on the server with a verifier
+	if srpVerifier != nil {
+		if err := app.APIClient.UpgradeToSRP(srpVerifier); err != nil {
+			fmt.Printf("[Core] Failed to upgrade account to SRP: %v\n", err)
+		} else {
+			usedSRP = true
+		}
+	}
+
+	// Never fall back to the password hash for this account again
+	if usedSRP {
+		if err := app.LocalStorage.SetSRPEnrolled(email); err != nil {
+			fmt.Printf("[Core] Failed to record SRP login: %v\n", err)
+		}
+	}
+
+	// Remember only the email; nothing that could be replayed to log in
+	if err := app.LocalStorage.SaveCredentials(email, rememberMe); err != nil {
+		// Non-critical error, just log it and continue
+		fmt.Printf("[Core] Failed to save remembered email: %v\n", err)
+	}
+
+	return nil
+}
+
+// AutoLogin reports whether a session is already active. Saved credentials
+// never contain a password equivalent, so a fresh start always needs the
+// master password to unlock the symmetric key.
+func (app *PastePalApp) AutoLogin() bool {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	return app.IsLoggedIn
 }
 
 // Logout ends the current user session
 func (app *PastePalApp) Logout() error {
 	app.mutex.Lock()
-	defer app.mutex.Unlock()
 
 	// Clear user data
 	app.CurrentUser = nil
-	app.SymmetricKey = nil
+	app.haltTrashPurge()
+	app.keys.Wipe()
+	app.dropSearchIndex()
+	app.keyPair = nil
+	app.settings = DefaultAccountSettings()
 	app.IsLoggedIn = false
+	app.locked = false
+	app.dropPendingLogin()
+	app.pendingSecret = ""
 	app.APIClient.SetAuthToken("")
 
-	// Clear local session
-	return app.LocalStorage.ClearUserSession()
+	// Clear local session. A quick unlock PIN only outlives closing the
+	// app, not logging out.
+	err := app.LocalStorage.ClearUserSession()
+	if pinErr := app.LocalStorage.DeletePIN(); err == nil {
+		err = pinErr
+	}
+	app.mutex.Unlock()
+
+	app.applyPendingAPIURL()
+	return err
+}
+
+// PasteOptions controls how a new paste is created
+type PasteOptions struct {
+	IsPublic       bool
+	ExpiresAt      time.Time
+	MaxAccessCount int
+	// Sign the paste with the user's signing key
+	Sign bool
+	// NoCompress keeps the title and content uncompressed even where
+	// compressPaste would compress them, for pastes that mix secrets the
+	// scan can't spot with text someone else controls
+	NoCompress bool
+	// Tags and folder the paste is filed under
+	Tags     []string
+	FolderID string
+	// Language for highlighting. Empty detects it from the title and content.
+	Language string
+	// Format to display the content in, one of the models.PasteFormat
+	// values. Empty picks one to suit the language.
+	Format string
+}
+
+// PasteView is a decrypted paste as shown to readers
+type PasteView struct {
+	Title    string
+	Content  string
+	Language string // Empty when unknown
+	Format   string // One of the models.PasteFormat values
+	// Who signed the paste, when that was checked
+	Author *PasteAuthor
 }
 
 // CreatePaste creates a new encrypted paste
-func (app *PastePalApp) CreatePaste(title, content string, isPublic bool, expiresAt, maxAccessCount int) (*models.Paste, error) {
+func (app *PastePalApp) CreatePaste(title, content string, opts PasteOptions) (*models.Paste, error) {
 	app.mutex.RLock()
 	defer app.mutex.RUnlock()
 
-	if !app.IsLoggedIn {
-		return nil, errors.New("not logged in")
+	return app.createPaste(title, content, opts)
+}
+
+// createPaste encrypts and uploads a paste. Callers must hold the lock.
+func (app *PastePalApp) createPaste(title, content string, opts PasteOptions) (*models.Paste, error) {
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	// Each paste gets its own key so it can be shared without exposing others
+	contentKey, err := crypto.GenerateSymmetricKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fields are bound to the paste ID, so it is chosen up front
+	pasteID, err := newPasteID()
+	if err != nil {
+		return nil, err
+	}
+
+	view := &PasteView{
+		Title:    title,
+		Content:  content,
+		Language: syntax.Lookup(opts.Language),
+		Format:   opts.Format,
+	}
+	if view.Language == "" {
+		view.Language = syntax.Detect(title, content)
+	}
+	switch view.Format {
+	case "":
+		view.Format = formatFor(view.Language)
+	case models.PasteFormatPlain, models.PasteFormatCode, models.PasteFormatMarkdown:
+	default:
+		return nil, fmt.Errorf("unknown paste format %q", view.Format)
 	}
 
 	// Encrypt title and content
-	encryptedTitle, err := crypto.EncryptData([]byte(title), app.SymmetricKey)
+	compress := compressPaste(view, opts)
+	encrypted, err := app.encryptPaste(pasteID, contentKey, view, compress)
 	if err != nil {
 		return nil, err
 	}
 
-	encryptedContent, err := crypto.EncryptData([]byte(content), app.SymmetricKey)
+	tags, err := normalizeTags(opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedTags, err := app.encryptTags(pasteID, tags)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create paste request
 	pasteReq := &models.CreatePasteRequest{
-		Title:    encryptedTitle,
-		Content:  encryptedContent,
-		IsPublic: isPublic,
+		ID:                  pasteID,
+		Title:               encrypted.Title,
+		Content:             encrypted.Content,
+		ExpiresAt:           opts.ExpiresAt,
+		IsPublic:            opts.IsPublic,
+		MaxAccessCount:      opts.MaxAccessCount,
+		EncryptedContentKey: encrypted.EncryptedContentKey,
+		EncryptionVersion:   models.PasteEncryptionEnvelope,
+		Language:            encrypted.Language,
+		Format:              encrypted.Format,
+		Tags:                encryptedTags,
+		FolderID:            opts.FolderID,
+	}
+
+	if opts.Sign {
+		pasteReq.Signature, pasteReq.SignatureVersion, err = app.signPaste(&models.Paste{
+			ID:                  pasteReq.ID,
+			Title:               pasteReq.Title,
+			Content:             pasteReq.Content,
+			ExpiresAt:           pasteReq.ExpiresAt,
+			IsPublic:            pasteReq.IsPublic,
+			MaxAccessCount:      pasteReq.MaxAccessCount,
+			EncryptedContentKey: pasteReq.EncryptedContentKey,
+			Language:            pasteReq.Language,
+			Format:              pasteReq.Format,
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Send to server
@@ -268,43 +501,68 @@ func (app *PastePalApp) CreatePaste(title, content string, isPublic bool, expire
 		return nil, err
 	}
 
+	// Servers that assign their own IDs need the fields bound to that one
+	if paste.ID != pasteID {
+		paste, err = app.rewritePaste(paste, contentKey, view, opts.Sign, compress)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(tags) > 0 {
+			paste.Tags, err = app.encryptTags(paste.ID, tags)
+			if err != nil {
+				return nil, err
+			}
+			if err := app.APIClient.SetPasteTags(paste.ID, paste.Tags); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Save locally
 	if err := app.LocalStorage.SavePasteLocally(paste); err != nil {
 		// Non-critical error, just log it
 		// In a real app, you'd use a logger here
 	}
 
+	app.indexPaste(paste, title, content)
+
 	return paste, nil
 }
 
-// GetPaste retrieves and decrypts a paste
-func (app *PastePalApp) GetPaste(pasteID string) (string, string, error) {
+// GetPaste retrieves and decrypts a paste, and checks who signed it
+func (app *PastePalApp) GetPaste(pasteID string) (*PasteView, error) {
 	app.mutex.RLock()
 	defer app.mutex.RUnlock()
 
-	if !app.IsLoggedIn {
-		return "", "", errors.New("not logged in")
+	if err := app.requireKeys(); err != nil {
+		return nil, err
 	}
 
 	// Get paste from server
 	paste, err := app.APIClient.GetPaste(pasteID)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	// Decrypt title
-	titleBytes, err := crypto.DecryptData(paste.Title, app.SymmetricKey)
+	contentKey, err := app.contentKey(paste)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	// Decrypt content
-	contentBytes, err := crypto.DecryptData(paste.Content, app.SymmetricKey)
+	view, err := decryptPasteView(paste, contentKey)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	return string(titleBytes), string(contentBytes), nil
+	// Only the user's own pastes can be opened here
+	if paste.UserID == app.CurrentUser.ID {
+		view.Author = verifyPaste(paste, app.CurrentUser.Email, app.CurrentUser.SigningPublicKey)
+	} else {
+		view.Author = verifyPaste(paste, "", "")
+	}
+
+	return view, nil
 }
 
 // GetUserPastes retrieves all pastes for the current user
@@ -316,7 +574,19 @@ func (app *PastePalApp) GetUserPastes() ([]*models.Paste, error) {
 		return nil, errors.New("not logged in")
 	}
 	fmt.Println("[Core] Getting user pastes")
-	return app.APIClient.GetUserPastes()
+	pastes, err := app.APIClient.GetUserPastes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep search in step with pastes created or deleted elsewhere
+	if app.requireKeys() == nil {
+		if err := app.syncSearchIndex(pastes); err != nil {
+			fmt.Printf("[Core] Failed to update search index: %v\n", err)
+		}
+	}
+
+	return pastes, nil
 }
 
 // GetConfigPath returns the default config path
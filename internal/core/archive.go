@@ -0,0 +1,258 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/archive"
+)
+
+// MinArchivePassphraseLength is the shortest passphrase accepted for a new
+// archive
+const MinArchivePassphraseLength = 8
+
+// ImportResult counts what happened to the pastes of an imported archive
+type ImportResult struct {
+	Imported int
+	// Pastes whose content the account already had
+	Duplicates int
+	// Pastes that expired since they were exported
+	Expired int
+}
+
+// ExportArchive writes all of the user's pastes, their folders and tags to
+// w as an archive encrypted with passphrase. It returns how many pastes were
+// exported.
+func (app *PastePalApp) ExportArchive(w io.Writer, passphrase string) (int, error) {
+	if len(passphrase) < MinArchivePassphraseLength {
+		return 0, fmt.Errorf("the passphrase must be at least %d characters", MinArchivePassphraseLength)
+	}
+
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return 0, err
+	}
+
+	pastes, err := app.APIClient.GetUserPastes()
+	if err != nil {
+		return 0, err
+	}
+
+	encryptedFolders, err := app.APIClient.GetFolders()
+	if err != nil {
+		return 0, err
+	}
+
+	a := &archive.Archive{
+		Manifest: archive.Manifest{
+			ExportedAt: time.Now().UTC(),
+			Server:     app.GetConfig().APIURL,
+			Account:    app.CurrentUser.Email,
+		},
+	}
+
+	for _, encrypted := range encryptedFolders {
+		folder, err := app.decryptFolder(encrypted)
+		if err != nil {
+			return 0, err
+		}
+		a.Folders = append(a.Folders, archive.Folder{ID: folder.ID, ParentID: folder.ParentID, Name: folder.Name})
+	}
+
+	for _, paste := range pastes {
+		contentKey, err := app.contentKey(paste)
+		if err != nil {
+			return 0, fmt.Errorf("paste %s: %v", paste.ID, err)
+		}
+
+		view, err := decryptPasteView(paste, contentKey)
+		if err != nil {
+			return 0, fmt.Errorf("paste %s: %v", paste.ID, err)
+		}
+
+		tags, err := app.decryptTags(paste)
+		if err != nil {
+			return 0, fmt.Errorf("paste %s: %v", paste.ID, err)
+		}
+
+		a.Pastes = append(a.Pastes, &archive.Paste{
+			ID:             paste.ID,
+			Title:          view.Title,
+			Content:        view.Content,
+			Language:       view.Language,
+			Format:         view.Format,
+			Tags:           tags,
+			FolderID:       paste.FolderID,
+			IsPublic:       paste.IsPublic,
+			Signed:         paste.Signature != "",
+			CreatedAt:      paste.CreatedAt,
+			ExpiresAt:      paste.ExpiresAt,
+			MaxAccessCount: paste.MaxAccessCount,
+		})
+	}
+
+	if err := archive.Write(w, a, passphrase); err != nil {
+		return 0, err
+	}
+
+	fmt.Printf("[Core] Exported %d pastes\n", len(a.Pastes))
+	return len(a.Pastes), nil
+}
+
+// ImportArchive adds the pastes of an archive to the user's account,
+// encrypting them with new keys. Pastes whose content the account already
+// has are skipped, so importing the same archive twice is harmless. The
+// result counts the pastes handled before any error.
+func (app *PastePalApp) ImportArchive(r io.Reader, passphrase string) (*ImportResult, error) {
+	// Deriving the archive key is slow, so it happens before taking the lock
+	a, err := archive.Read(r, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	seen, err := app.contentHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	folderIDs, err := app.importFolders(a.Folders)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	now := time.Now()
+	for _, paste := range a.Pastes {
+		hash := archive.ContentHash(paste.Content)
+		if seen[hash] {
+			result.Duplicates++
+			continue
+		}
+		if !paste.ExpiresAt.IsZero() && paste.ExpiresAt.Before(now) {
+			result.Expired++
+			continue
+		}
+
+		_, err := app.createPaste(paste.Title, paste.Content, PasteOptions{
+			IsPublic:       paste.IsPublic,
+			ExpiresAt:      paste.ExpiresAt,
+			MaxAccessCount: paste.MaxAccessCount,
+			Sign:           paste.Signed,
+			NoCompress:     true,
+			Tags:           paste.Tags,
+			FolderID:       folderIDs[paste.FolderID],
+			Language:       paste.Language,
+			Format:         paste.Format,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to import %q: %v", paste.Title, err)
+		}
+
+		seen[hash] = true
+		result.Imported++
+	}
+
+	fmt.Printf("[Core] Imported %d pastes, skipped %d duplicates and %d expired\n", result.Imported, result.Duplicates, result.Expired)
+	return result, nil
+}
+
+// contentHashes returns the content hashes of the user's pastes. Callers
+// must hold the lock.
+func (app *PastePalApp) contentHashes() (map[string]bool, error) {
+	pastes, err := app.APIClient.GetUserPastes()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]bool, len(pastes))
+	for _, paste := range pastes {
+		contentKey, err := app.contentKey(paste)
+		if err != nil {
+			fmt.Printf("[Core] Skipping paste %s when checking for duplicates: %v\n", paste.ID, err)
+			continue
+		}
+
+		_, content, err := decryptPaste(paste, contentKey)
+		if err != nil {
+			fmt.Printf("[Core] Skipping paste %s when checking for duplicates: %v\n", paste.ID, err)
+			continue
+		}
+		hashes[archive.ContentHash(content)] = true
+	}
+
+	return hashes, nil
+}
+
+// importFolders recreates the folders of an archive, reusing folders of the
+// same name in the same place. It returns the account's folder ID for each
+// archived folder ID. Callers must hold the lock.
+func (app *PastePalApp) importFolders(archived []archive.Folder) (map[string]string, error) {
+	encrypted, err := app.APIClient.GetFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	// Existing folders by parent ID and name
+	type place struct{ parentID, name string }
+	existing := make(map[place]string)
+	for _, folder := range encrypted {
+		decrypted, err := app.decryptFolder(folder)
+		if err != nil {
+			return nil, err
+		}
+		existing[place{decrypted.ParentID, decrypted.Name}] = decrypted.ID
+	}
+
+	inArchive := make(map[string]bool, len(archived))
+	for _, folder := range archived {
+		inArchive[folder.ID] = true
+	}
+
+	// Parents are created before their children. A folder whose parent is
+	// missing from the archive goes to the top level.
+	ids := make(map[string]string, len(archived))
+	remaining := archived
+	for len(remaining) > 0 {
+		var waiting []archive.Folder
+		for _, folder := range remaining {
+			parentID := ""
+			if folder.ParentID != "" && inArchive[folder.ParentID] {
+				var ok bool
+				if parentID, ok = ids[folder.ParentID]; !ok {
+					waiting = append(waiting, folder)
+					continue
+				}
+			}
+
+			if id, ok := existing[place{parentID, folder.Name}]; ok {
+				ids[folder.ID] = id
+				continue
+			}
+
+			created, err := app.createFolder(folder.Name, parentID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create folder %q: %v", folder.Name, err)
+			}
+			existing[place{parentID, created.Name}] = created.ID
+			ids[folder.ID] = created.ID
+		}
+
+		if len(waiting) == len(remaining) {
+			return nil, errors.New("archive folders contain a cycle")
+		}
+		remaining = waiting
+	}
+
+	return ids, nil
+}
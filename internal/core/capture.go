@@ -0,0 +1,83 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// RecentPaste is one of the user's newest pastes with its title decrypted
+type RecentPaste struct {
+	Paste *models.Paste
+	Title string
+}
+
+// QuickCapture creates a public paste of content that expires after the
+// configured quick capture time, and returns it with its share link
+func (app *PastePalApp) QuickCapture(content string) (*models.Paste, string, error) {
+	hours := app.GetConfig().QuickCaptureExpiryHours
+
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	// A link can only be made for a public paste. The clipboard can hold
+	// anything, so the capture is never compressed.
+	paste, err := app.createPaste("Quick capture "+time.Now().Format("2006-01-02 15:04"), content, PasteOptions{
+		IsPublic:   true,
+		ExpiresAt:  time.Now().Add(time.Duration(hours) * time.Hour),
+		Sign:       true,
+		NoCompress: true,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	link, err := app.shareLink(paste)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fmt.Printf("[Core] Quick captured paste %s, expiring in %d hours\n", paste.ID, hours)
+	return paste, link, nil
+}
+
+// RecentPastes returns the newest limit of pastes with their titles
+// decrypted. Pastes that can't be decrypted are left out.
+func (app *PastePalApp) RecentPastes(pastes []*models.Paste, limit int) ([]RecentPaste, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	newest := make([]*models.Paste, len(pastes))
+	copy(newest, pastes)
+	sort.SliceStable(newest, func(i, j int) bool {
+		return newest[i].CreatedAt.After(newest[j].CreatedAt)
+	})
+
+	var recent []RecentPaste
+	for _, paste := range newest {
+		if len(recent) == limit {
+			break
+		}
+
+		contentKey, err := app.contentKey(paste)
+		if err != nil {
+			fmt.Printf("[Core] Skipping paste %s in recent pastes: %v\n", paste.ID, err)
+			continue
+		}
+
+		title, _, err := decryptPaste(paste, contentKey)
+		if err != nil {
+			fmt.Printf("[Core] Skipping paste %s in recent pastes: %v\n", paste.ID, err)
+			continue
+		}
+		recent = append(recent, RecentPaste{Paste: paste, Title: title})
+	}
+
+	return recent, nil
+}
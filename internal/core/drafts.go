@@ -0,0 +1,121 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+)
+
+// Draft is a paste that is still being written. Drafts stay on this
+// device, encrypted under the account key.
+type Draft struct {
+	// Empty until the draft is first saved
+	ID       string    `json:"id"`
+	SavedAt  time.Time `json:"saved_at"`
+	Title    string    `json:"title"`
+	Content  string    `json:"content"`
+	Language string    `json:"language,omitempty"`
+	Format   string    `json:"format,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	FolderID string    `json:"folder_id,omitempty"`
+	IsPublic bool      `json:"is_public"`
+	Sign     bool      `json:"sign"`
+	// Keep the paste uncompressed, see PasteOptions.NoCompress
+	NoCompress bool `json:"no_compress,omitempty"`
+	// How long the paste will last, 0 for no expiry
+	ExpiryHours int `json:"expiry_hours,omitempty"`
+}
+
+// draftAAD binds an encrypted draft to its user and ID, so drafts can't be
+// swapped between files
+func draftAAD(userID, draftID string) []byte {
+	return lengthPrefixed("pastepal-draft-v1", userID, draftID)
+}
+
+// SaveDraft encrypts a draft and stores it, giving it an ID the first time
+func (app *PastePalApp) SaveDraft(draft *Draft) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+
+	if draft.ID == "" {
+		id, err := newPasteID()
+		if err != nil {
+			return err
+		}
+		draft.ID = id
+	}
+	draft.SavedAt = time.Now()
+
+	data, err := json.Marshal(draft)
+	if err != nil {
+		return err
+	}
+	defer clear(data)
+
+	encrypted, err := crypto.EncryptEnvelope(data, app.keys.SymmetricKey(), draftAAD(app.CurrentUser.ID, draft.ID), app.pasteEnvelope(data, false))
+	if err != nil {
+		return err
+	}
+
+	return app.LocalStorage.SaveDraft(app.CurrentUser.ID, draft.ID, encrypted)
+}
+
+// GetDrafts returns the user's drafts, most recently saved first. Drafts
+// that can't be decrypted are left out.
+func (app *PastePalApp) GetDrafts() ([]*Draft, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := app.LocalStorage.GetDrafts(app.CurrentUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	drafts := make([]*Draft, 0, len(encrypted))
+	for id, ciphertext := range encrypted {
+		data, err := crypto.DecryptEnvelope(ciphertext, app.keys.SymmetricKey(), draftAAD(app.CurrentUser.ID, id))
+		if err != nil {
+			fmt.Printf("[Core] Skipping unreadable draft %s: %v\n", id, err)
+			continue
+		}
+
+		draft := &Draft{}
+		err = json.Unmarshal(data, draft)
+		clear(data)
+		if err != nil {
+			fmt.Printf("[Core] Skipping unreadable draft %s: %v\n", id, err)
+			continue
+		}
+		draft.ID = id
+		drafts = append(drafts, draft)
+	}
+
+	sort.Slice(drafts, func(i, j int) bool {
+		return drafts[i].SavedAt.After(drafts[j].SavedAt)
+	})
+	return drafts, nil
+}
+
+// DiscardDraft deletes one of the user's drafts
+func (app *PastePalApp) DiscardDraft(draftID string) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn {
+		return errors.New("not logged in")
+	}
+
+	return app.LocalStorage.DeleteDraft(app.CurrentUser.ID, draftID)
+}
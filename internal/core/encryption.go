@@ -0,0 +1,385 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+	"github.com/JacobRWebb/PastePal-OS/internal/secrets"
+	"github.com/JacobRWebb/PastePal-OS/internal/syntax"
+)
+
+// pasteAADDomain separates paste associated data from any other use of a key
+const pasteAADDomain = "pastepal-paste-aad-v1"
+
+// Field names bound into the associated data of each encrypted paste field,
+// so fields can't be swapped with each other
+const (
+	pasteFieldTitle      = "title"
+	pasteFieldContent    = "content"
+	pasteFieldContentKey = "content_key"
+	pasteFieldTags       = "tags"
+	pasteFieldLanguage   = "language"
+	pasteFieldFormat     = "format"
+)
+
+// errPasteDowngraded is returned for a paste in the legacy format after all of
+// the user's pastes were upgraded, which only a tampering server would serve
+var errPasteDowngraded = errors.New("paste is in the legacy format although all pastes were upgraded; it may have been tampered with")
+
+// encryptedPaste holds the encrypted fields of a paste
+type encryptedPaste struct {
+	Title               string
+	Content             string
+	Language            string
+	Format              string
+	EncryptedContentKey string
+}
+
+// lengthPrefixed joins fields, each prefixed with its length as a big-endian
+// uint32, so that no two field lists encode to the same bytes
+func lengthPrefixed(fields ...string) []byte {
+	var encoded []byte
+	for _, field := range fields {
+		encoded = binary.BigEndian.AppendUint32(encoded, uint32(len(field)))
+		encoded = append(encoded, field...)
+	}
+	return encoded
+}
+
+// pasteFieldAAD returns the associated data for one field of a paste
+func pasteFieldAAD(ownerID, pasteID, field string) []byte {
+	return lengthPrefixed(pasteAADDomain, ownerID, pasteID, field)
+}
+
+// newPasteID generates the ID a new paste's fields are bound to
+func newPasteID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// pasteEnvelope picks how data is prepared for encryption. Compression is
+// left off unless allowed, since it would let data that mixes secrets with
+// text someone else controls leak the secrets through its size. Only new
+// pastes are allowed it, by compressPaste. Everything else stays
+// uncompressed: drafts, templates and the search index may hold anything,
+// and pastes written again may have had compression turned off, which isn't
+// recorded.
+func (app *PastePalApp) pasteEnvelope(data []byte, compress bool) crypto.EnvelopeOptions {
+	opts := crypto.EnvelopeOptions{
+		Compression: crypto.CompressionNone,
+		Padding:     app.padding(),
+	}
+	if compress {
+		opts.Compression = crypto.ChooseCompression(data)
+	}
+	return opts
+}
+
+// compressPaste decides whether a new paste's title and content are
+// compressed. The danger is a paste that mixes a secret with text an
+// attacker controls: whoever can see the ciphertext size, which includes
+// the server for every paste, can guess the secret from how well it
+// compresses. Pastes holding likely secrets are therefore never compressed.
+// Public pastes aren't either, since their text is the likeliest to come
+// from someone else. Compression is left to core rather than to each caller
+// so no caller can forget this.
+func compressPaste(view *PasteView, opts PasteOptions) bool {
+	if opts.NoCompress || opts.IsPublic {
+		return false
+	}
+	return len(secrets.Scan(view.Title)) == 0 && len(secrets.Scan(view.Content)) == 0
+}
+
+// padding maps the account's padding scheme to the crypto one. Callers must
+// hold the lock.
+func (app *PastePalApp) padding() crypto.Padding {
+	switch app.settings.Padding {
+	case PaddingOff:
+		return crypto.PaddingNone
+	case PaddingPowerOfTwo:
+		return crypto.PaddingPowerOfTwo
+	default:
+		return crypto.PaddingPadme
+	}
+}
+
+// encryptPaste encrypts the fields of one of the user's pastes with its
+// content key, bound to the user and the paste ID. The language and format
+// are left out when empty.
+func (app *PastePalApp) encryptPaste(pasteID string, contentKey []byte, view *PasteView, compress bool) (*encryptedPaste, error) {
+	ownerID := app.CurrentUser.ID
+
+	encryptedTitle, err := crypto.EncryptEnvelope([]byte(view.Title), contentKey, pasteFieldAAD(ownerID, pasteID, pasteFieldTitle), app.pasteEnvelope([]byte(view.Title), compress))
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedContent, err := crypto.EncryptEnvelope([]byte(view.Content), contentKey, pasteFieldAAD(ownerID, pasteID, pasteFieldContent), app.pasteEnvelope([]byte(view.Content), compress))
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypted with the content key, so the paste is displayed the same way
+	// for people it is shared with
+	encryptedLanguage, err := app.encryptOptionalField(view.Language, contentKey, pasteFieldAAD(ownerID, pasteID, pasteFieldLanguage))
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedFormat, err := app.encryptOptionalField(view.Format, contentKey, pasteFieldAAD(ownerID, pasteID, pasteFieldFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedContentKey, err := crypto.EncryptDataWithAAD(contentKey, app.keys.SymmetricKey(), pasteFieldAAD(ownerID, pasteID, pasteFieldContentKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptedPaste{
+		Title:               encryptedTitle,
+		Content:             encryptedContent,
+		Language:            encryptedLanguage,
+		Format:              encryptedFormat,
+		EncryptedContentKey: encryptedContentKey,
+	}, nil
+}
+
+// encryptOptionalField encrypts a short, uncompressed paste field, leaving
+// it empty when there is no value
+func (app *PastePalApp) encryptOptionalField(value string, contentKey, aad []byte) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	return crypto.EncryptEnvelope([]byte(value), contentKey, aad, app.pasteEnvelope([]byte(value), false))
+}
+
+// contentKey returns the key a paste owned by the user is encrypted with
+func (app *PastePalApp) contentKey(paste *models.Paste) ([]byte, error) {
+	switch paste.EncryptionVersion {
+	case models.PasteEncryptionEnvelope:
+		return crypto.DecryptDataWithAAD(paste.EncryptedContentKey, app.keys.SymmetricKey(), pasteFieldAAD(paste.UserID, paste.ID, pasteFieldContentKey))
+	case models.PasteEncryptionLegacy:
+		if app.LocalStorage.PastesUpgraded(app.CurrentUser.ID) {
+			return nil, errPasteDowngraded
+		}
+
+		// Legacy pastes use the symmetric key directly. A wrapped key without
+		// associated data could have been moved from another paste.
+		if paste.EncryptedContentKey != "" {
+			return nil, errors.New("legacy paste has an unexpected content key")
+		}
+		return app.keys.SymmetricKey(), nil
+	default:
+		return nil, fmt.Errorf("unsupported paste encryption version %d", paste.EncryptionVersion)
+	}
+}
+
+// decryptPaste decrypts the title and content of a paste
+func decryptPaste(paste *models.Paste, contentKey []byte) (string, string, error) {
+	var titleAAD, contentAAD []byte
+	decrypt := crypto.DecryptDataWithAAD
+	switch paste.EncryptionVersion {
+	case models.PasteEncryptionEnvelope:
+		decrypt = crypto.DecryptEnvelope
+		titleAAD = pasteFieldAAD(paste.UserID, paste.ID, pasteFieldTitle)
+		contentAAD = pasteFieldAAD(paste.UserID, paste.ID, pasteFieldContent)
+	case models.PasteEncryptionLegacy:
+		// Legacy fields have no associated data
+	default:
+		return "", "", fmt.Errorf("unsupported paste encryption version %d", paste.EncryptionVersion)
+	}
+
+	// Decrypt title
+	titleBytes, err := decrypt(paste.Title, contentKey, titleAAD)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Decrypt content
+	contentBytes, err := decrypt(paste.Content, contentKey, contentAAD)
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(titleBytes), string(contentBytes), nil
+}
+
+// decryptOptionalField decrypts a field added after the legacy formats.
+// Pastes created before the field existed have none.
+func decryptOptionalField(paste *models.Paste, value, field string, contentKey []byte) (string, error) {
+	if value == "" || paste.EncryptionVersion != models.PasteEncryptionEnvelope {
+		return "", nil
+	}
+
+	plaintext, err := crypto.DecryptEnvelope(value, contentKey, pasteFieldAAD(paste.UserID, paste.ID, field))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// decryptPasteView decrypts everything about a paste that is shown to readers
+func decryptPasteView(paste *models.Paste, contentKey []byte) (*PasteView, error) {
+	title, content, err := decryptPaste(paste, contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	language, err := decryptOptionalField(paste, paste.Language, pasteFieldLanguage, contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := decryptOptionalField(paste, paste.Format, pasteFieldFormat, contentKey)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = formatFor(language)
+	}
+
+	return &PasteView{Title: title, Content: content, Language: language, Format: format}, nil
+}
+
+// formatFor picks how to display a paste with the given language when no
+// format was chosen
+func formatFor(language string) string {
+	switch language {
+	case "", syntax.PlainText:
+		return models.PasteFormatPlain
+	case syntax.Markdown:
+		return models.PasteFormatMarkdown
+	default:
+		return models.PasteFormatCode
+	}
+}
+
+// rewritePaste stores a paste's fields encrypted in the current format, bound
+// to the paste's ID as the server knows it
+func (app *PastePalApp) rewritePaste(paste *models.Paste, contentKey []byte, view *PasteView, sign, compress bool) (*models.Paste, error) {
+	encrypted, err := app.encryptPaste(paste.ID, contentKey, view, compress)
+	if err != nil {
+		return nil, err
+	}
+
+	updateReq := &models.UpdatePasteRequest{
+		Title:               encrypted.Title,
+		Content:             encrypted.Content,
+		EncryptedContentKey: encrypted.EncryptedContentKey,
+		EncryptionVersion:   models.PasteEncryptionEnvelope,
+		Language:            encrypted.Language,
+		Format:              encrypted.Format,
+	}
+
+	// The signature covers the ciphertext, so it has to be made again
+	if sign {
+		updateReq.Signature, updateReq.SignatureVersion, err = app.signPaste(&models.Paste{
+			ID:                  paste.ID,
+			Title:               updateReq.Title,
+			Content:             updateReq.Content,
+			ExpiresAt:           paste.ExpiresAt,
+			IsPublic:            paste.IsPublic,
+			MaxAccessCount:      paste.MaxAccessCount,
+			EncryptedContentKey: updateReq.EncryptedContentKey,
+			Language:            updateReq.Language,
+			Format:              updateReq.Format,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := app.APIClient.UpdatePaste(paste.ID, updateReq)
+	if err != nil {
+		return nil, err
+	}
+
+	app.indexPaste(updated, view.Title, view.Content)
+	return updated, nil
+}
+
+// upgradePaste re-encrypts a legacy paste with its fields bound to it,
+// giving it a key of its own if it doesn't have one yet
+func (app *PastePalApp) upgradePaste(paste *models.Paste) (*models.Paste, error) {
+	contentKey, err := app.contentKey(paste)
+	if err != nil {
+		return nil, err
+	}
+
+	title, content, err := decryptPaste(paste, contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sharing the symmetric key would expose every paste. Pastes that already
+	// have a key keep it, so people they were shared with keep access.
+	if paste.EncryptedContentKey == "" {
+		contentKey, err = crypto.GenerateSymmetricKey()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Legacy pastes never had a language or format
+	view := &PasteView{Title: title, Content: content}
+	updated, err := app.rewritePaste(paste, contentKey, view, paste.Signature != "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := app.LocalStorage.SavePasteLocally(updated); err != nil {
+		// Non-critical, the cache is refreshed on the next save
+	}
+
+	return updated, nil
+}
+
+// UpgradePasteEncryption re-encrypts the user's legacy pastes so that their
+// fields are bound to the paste they belong to. Once every paste is upgraded,
+// legacy pastes are refused on this device. It returns the number of pastes
+// upgraded.
+func (app *PastePalApp) UpgradePasteEncryption() (int, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return 0, err
+	}
+
+	if app.LocalStorage.PastesUpgraded(app.CurrentUser.ID) {
+		return 0, nil
+	}
+
+	pastes, err := app.APIClient.GetUserPastes()
+	if err != nil {
+		return 0, err
+	}
+
+	upgraded := 0
+	for _, paste := range pastes {
+		if paste.EncryptionVersion != models.PasteEncryptionLegacy {
+			continue
+		}
+
+		if _, err := app.upgradePaste(paste); err != nil {
+			return upgraded, fmt.Errorf("failed to upgrade paste %s: %w", paste.ID, err)
+		}
+		upgraded++
+	}
+
+	if err := app.LocalStorage.SetPastesUpgraded(app.CurrentUser.ID); err != nil {
+		return upgraded, err
+	}
+
+	fmt.Printf("[Core] Upgraded encryption of %d pastes\n", upgraded)
+	return upgraded, nil
+}
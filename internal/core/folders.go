@@ -0,0 +1,494 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// folderAADDomain separates folder name associated data from any other use
+// of the symmetric key
+const folderAADDomain = "pastepal-folder-aad-v1"
+
+// maxOrganizerNameLength limits folder and tag names, in characters
+const maxOrganizerNameLength = 100
+
+// Folder is one of the user's folders with its name decrypted
+type Folder struct {
+	ID        string
+	ParentID  string // Empty for top-level folders
+	Name      string
+	CreatedAt time.Time
+}
+
+// PasteFilter narrows down a list of pastes
+type PasteFilter struct {
+	// Tag only keeps pastes with this tag, ignoring case. Empty keeps all.
+	Tag string
+	// FolderID only keeps pastes in this folder or below it. Empty keeps all.
+	FolderID string
+	// Since and Until only keep pastes created in that range, including
+	// both ends. A zero time leaves that end open.
+	Since time.Time
+	Until time.Time
+}
+
+// PasteOrganization is where a paste is filed
+type PasteOrganization struct {
+	FolderID string
+	Tags     []string
+}
+
+// folderNameAAD binds an encrypted folder name to its owner and folder
+func folderNameAAD(ownerID, folderID string) []byte {
+	return lengthPrefixed(folderAADDomain, ownerID, folderID)
+}
+
+// cleanName trims a folder or tag name and checks its length
+func cleanName(kind, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New(kind + " name is required")
+	}
+	if utf8.RuneCountInString(name) > maxOrganizerNameLength {
+		return "", errors.New(kind + " name is too long")
+	}
+	return name, nil
+}
+
+// normalizeTags cleans up tags, drops duplicates ignoring case and sorts them
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			continue
+		}
+
+		tag, err := cleanName("tag", tag)
+		if err != nil {
+			return nil, err
+		}
+
+		key := strings.ToLower(tag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i]) < strings.ToLower(result[j])
+	})
+	return result, nil
+}
+
+// encryptTags encrypts a paste's tags with the symmetric key rather than the
+// paste's key, so people a paste is shared with don't see them
+func (app *PastePalApp) encryptTags(pasteID string, tags []string) (string, error) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", err
+	}
+
+	aad := pasteFieldAAD(app.CurrentUser.ID, pasteID, pasteFieldTags)
+	return crypto.EncryptEnvelope(data, app.keys.SymmetricKey(), aad, app.pasteEnvelope(data, false))
+}
+
+// decryptTags decrypts the tags of one of the user's pastes
+func (app *PastePalApp) decryptTags(paste *models.Paste) ([]string, error) {
+	if paste.Tags == "" {
+		return nil, nil
+	}
+
+	aad := pasteFieldAAD(paste.UserID, paste.ID, pasteFieldTags)
+	data, err := crypto.DecryptEnvelope(paste.Tags, app.keys.SymmetricKey(), aad)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// encryptFolderName encrypts a folder name bound to the folder's ID
+func (app *PastePalApp) encryptFolderName(folderID, name string) (string, error) {
+	aad := folderNameAAD(app.CurrentUser.ID, folderID)
+	return crypto.EncryptEnvelope([]byte(name), app.keys.SymmetricKey(), aad, app.pasteEnvelope([]byte(name), false))
+}
+
+// decryptFolder decrypts the name of one of the user's folders
+func (app *PastePalApp) decryptFolder(folder *models.Folder) (*Folder, error) {
+	name, err := crypto.DecryptEnvelope(folder.Name, app.keys.SymmetricKey(), folderNameAAD(folder.UserID, folder.ID))
+	if err != nil {
+		return nil, errors.New("failed to decrypt folder name")
+	}
+
+	return &Folder{
+		ID:        folder.ID,
+		ParentID:  folder.ParentID,
+		Name:      string(name),
+		CreatedAt: folder.CreatedAt,
+	}, nil
+}
+
+// GetFolders retrieves and decrypts the user's folders
+func (app *PastePalApp) GetFolders() ([]*Folder, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	return app.getFolders()
+}
+
+// getFolders retrieves and decrypts the user's folders. Callers must hold
+// the lock.
+func (app *PastePalApp) getFolders() ([]*Folder, error) {
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := app.APIClient.GetFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make([]*Folder, 0, len(encrypted))
+	for _, folder := range encrypted {
+		decrypted, err := app.decryptFolder(folder)
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, decrypted)
+	}
+
+	return folders, nil
+}
+
+// CreateFolder creates a folder, at the top level when parentID is empty
+func (app *PastePalApp) CreateFolder(name, parentID string) (*Folder, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	return app.createFolder(name, parentID)
+}
+
+// createFolder encrypts the name of a new folder and creates it. Callers
+// must hold the lock.
+func (app *PastePalApp) createFolder(name, parentID string) (*Folder, error) {
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	name, err := cleanName("folder", name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The name is bound to the folder ID, so it is chosen up front like a
+	// paste ID
+	folderID, err := newPasteID()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedName, err := app.encryptFolderName(folderID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := app.APIClient.CreateFolder(&models.CreateFolderRequest{
+		ID:       folderID,
+		ParentID: parentID,
+		Name:     encryptedName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers that assign their own IDs need the name bound to that one
+	if folder.ID != folderID {
+		encryptedName, err = app.encryptFolderName(folder.ID, name)
+		if err != nil {
+			return nil, err
+		}
+
+		folder, err = app.APIClient.UpdateFolder(folder.ID, &models.UpdateFolderRequest{
+			ParentID: folder.ParentID,
+			Name:     encryptedName,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return app.decryptFolder(folder)
+}
+
+// RenameFolder gives a folder a new name
+func (app *PastePalApp) RenameFolder(folderID, name string) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+
+	name, err := cleanName("folder", name)
+	if err != nil {
+		return err
+	}
+
+	folder, _, err := app.findFolder(folderID)
+	if err != nil {
+		return err
+	}
+
+	encryptedName, err := app.encryptFolderName(folderID, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = app.APIClient.UpdateFolder(folderID, &models.UpdateFolderRequest{
+		ParentID: folder.ParentID,
+		Name:     encryptedName,
+	})
+	return err
+}
+
+// MoveFolder moves a folder under another one, or to the top level when
+// parentID is empty
+func (app *PastePalApp) MoveFolder(folderID, parentID string) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+
+	folder, folders, err := app.findFolder(folderID)
+	if err != nil {
+		return err
+	}
+
+	// Walk up from the new parent to make sure the folder isn't moved into
+	// itself. A walk longer than there are folders means the server's
+	// folders already form a cycle.
+	parents := make(map[string]string, len(folders))
+	for _, f := range folders {
+		parents[f.ID] = f.ParentID
+	}
+	steps := 0
+	for id := parentID; id != ""; id = parents[id] {
+		if id == folderID {
+			return errors.New("a folder can't be moved into itself")
+		}
+		if _, ok := parents[id]; !ok {
+			return errors.New("folder not found")
+		}
+		if steps++; steps > len(folders) {
+			return errors.New("folders form a cycle")
+		}
+	}
+
+	// The name is bound to the folder, not its parent, so it moves unchanged
+	_, err = app.APIClient.UpdateFolder(folderID, &models.UpdateFolderRequest{
+		ParentID: parentID,
+		Name:     folder.Name,
+	})
+	return err
+}
+
+// findFolder looks up one of the user's folders on the server, along with
+// all of them
+func (app *PastePalApp) findFolder(folderID string) (*models.Folder, []*models.Folder, error) {
+	folders, err := app.APIClient.GetFolders()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, folder := range folders {
+		if folder.ID == folderID {
+			return folder, folders, nil
+		}
+	}
+	return nil, nil, errors.New("folder not found")
+}
+
+// MovePaste files a paste in a folder, or at the top level when folderID is
+// empty
+func (app *PastePalApp) MovePaste(pasteID, folderID string) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn {
+		return errors.New("not logged in")
+	}
+
+	return app.APIClient.MovePaste(pasteID, folderID)
+}
+
+// SetPasteTags replaces the tags of a paste
+func (app *PastePalApp) SetPasteTags(pasteID string, tags []string) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	encryptedTags, err := app.encryptTags(pasteID, tags)
+	if err != nil {
+		return err
+	}
+
+	return app.APIClient.SetPasteTags(pasteID, encryptedTags)
+}
+
+// GetPasteOrganization retrieves the folder and tags of a paste
+func (app *PastePalApp) GetPasteOrganization(pasteID string) (*PasteOrganization, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	paste, err := app.APIClient.GetPaste(pasteID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := app.decryptTags(paste)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasteOrganization{FolderID: paste.FolderID, Tags: tags}, nil
+}
+
+// CollectTags returns every tag used by the pastes, sorted
+func (app *PastePalApp) CollectTags(pastes []*models.Paste) []string {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if app.requireKeys() != nil {
+		return nil
+	}
+
+	var all []string
+	for _, paste := range pastes {
+		tags, err := app.decryptTags(paste)
+		if err != nil {
+			continue
+		}
+		all = append(all, tags...)
+	}
+
+	tags, _ := normalizeTags(all)
+	return tags
+}
+
+// FilterPastes keeps the pastes matching the filter. Pastes whose tags can't
+// be decrypted never match a tag.
+func (app *PastePalApp) FilterPastes(pastes []*models.Paste, folders []*Folder, filter PasteFilter) ([]*models.Paste, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	return app.filterPastes(pastes, folders, filter)
+}
+
+// filterPastes keeps the pastes matching the filter. Callers must hold the
+// lock.
+func (app *PastePalApp) filterPastes(pastes []*models.Paste, folders []*Folder, filter PasteFilter) ([]*models.Paste, error) {
+	if filter.Tag != "" {
+		if err := app.requireKeys(); err != nil {
+			return nil, err
+		}
+	}
+
+	// The folder and every folder below it
+	var inFolder map[string]bool
+	if filter.FolderID != "" {
+		inFolder = map[string]bool{filter.FolderID: true}
+		for added := true; added; {
+			added = false
+			for _, folder := range folders {
+				if inFolder[folder.ParentID] && !inFolder[folder.ID] {
+					inFolder[folder.ID] = true
+					added = true
+				}
+			}
+		}
+	}
+
+	var result []*models.Paste
+	for _, paste := range pastes {
+		if inFolder != nil && !inFolder[paste.FolderID] {
+			continue
+		}
+		if !filter.Since.IsZero() && paste.CreatedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && paste.CreatedAt.After(filter.Until) {
+			continue
+		}
+
+		if filter.Tag != "" {
+			tags, err := app.decryptTags(paste)
+			if err != nil || !containsFold(tags, filter.Tag) {
+				continue
+			}
+		}
+
+		result = append(result, paste)
+	}
+	return result, nil
+}
+
+// containsFold reports whether a list holds a string, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// FolderPath returns the names leading to a folder, joined with slashes
+func FolderPath(folders []*Folder, folderID string) string {
+	byID := make(map[string]*Folder, len(folders))
+	for _, folder := range folders {
+		byID[folder.ID] = folder
+	}
+
+	var names []string
+	for id := folderID; id != "" && len(names) <= len(folders); {
+		folder, ok := byID[id]
+		if !ok {
+			break
+		}
+		names = append([]string{folder.Name}, names...)
+		id = folder.ParentID
+	}
+	return strings.Join(names, " / ")
+}
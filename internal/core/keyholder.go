@@ -0,0 +1,117 @@
+package core
+
+// KeyHolder keeps the user's key material in memory that is locked against
+// being swapped to disk where the platform allows it, and wipes it on
+// demand. It is guarded by the app mutex.
+type KeyHolder struct {
+	master    *secureBuffer
+	symmetric *secureBuffer
+	private   *secureBuffer
+	signing   *secureBuffer
+}
+
+// secureBuffer is a fixed-size buffer outside the Go heap where possible, so
+// the garbage collector never leaves copies behind
+type secureBuffer struct {
+	data   []byte
+	locked bool
+	free   func()
+}
+
+// newSecureBuffer copies secret into a secure buffer and wipes the original
+func newSecureBuffer(secret []byte) *secureBuffer {
+	if len(secret) == 0 {
+		return nil
+	}
+
+	data, locked, free := allocLocked(len(secret))
+	copy(data, secret)
+	clear(secret)
+
+	return &secureBuffer{data: data, locked: locked, free: free}
+}
+
+// bytes returns the buffer contents, or nil for an empty buffer
+func (b *secureBuffer) bytes() []byte {
+	if b == nil {
+		return nil
+	}
+	return b.data
+}
+
+// wipe zeroes the buffer and releases its memory
+func (b *secureBuffer) wipe() {
+	if b == nil {
+		return
+	}
+
+	clear(b.data)
+	b.free()
+	b.data = nil
+}
+
+// SetMasterKey stores the master key derived from the password and wipes
+// the given slice. It is only held while a login waits for its second
+// factor.
+func (h *KeyHolder) SetMasterKey(key []byte) {
+	h.master.wipe()
+	h.master = newSecureBuffer(key)
+}
+
+// MasterKey returns the master key, or nil when none is held
+func (h *KeyHolder) MasterKey() []byte {
+	return h.master.bytes()
+}
+
+// SetSymmetricKey stores the symmetric key and wipes the given slice
+func (h *KeyHolder) SetSymmetricKey(key []byte) {
+	h.symmetric.wipe()
+	h.symmetric = newSecureBuffer(key)
+}
+
+// SymmetricKey returns the symmetric key, or nil when none is held. The
+// slice is only valid until the holder is wiped.
+func (h *KeyHolder) SymmetricKey() []byte {
+	return h.symmetric.bytes()
+}
+
+// SetPrivateKey stores the sharing private key and wipes the given slice
+func (h *KeyHolder) SetPrivateKey(key []byte) {
+	h.private.wipe()
+	h.private = newSecureBuffer(key)
+}
+
+// PrivateKey returns the sharing private key, or nil when none is held
+func (h *KeyHolder) PrivateKey() []byte {
+	return h.private.bytes()
+}
+
+// SetSigningKey stores the signing key and wipes the given slice
+func (h *KeyHolder) SetSigningKey(key []byte) {
+	h.signing.wipe()
+	h.signing = newSecureBuffer(key)
+}
+
+// SigningKey returns the signing key, or nil when none is held
+func (h *KeyHolder) SigningKey() []byte {
+	return h.signing.bytes()
+}
+
+// MemoryLocked reports whether every held key is in locked memory
+func (h *KeyHolder) MemoryLocked() bool {
+	for _, buffer := range []*secureBuffer{h.master, h.symmetric, h.private, h.signing} {
+		if buffer != nil && !buffer.locked {
+			return false
+		}
+	}
+	return true
+}
+
+// Wipe zeroes and releases all held keys
+func (h *KeyHolder) Wipe() {
+	h.master.wipe()
+	h.symmetric.wipe()
+	h.private.wipe()
+	h.signing.wipe()
+	h.master, h.symmetric, h.private, h.signing = nil, nil, nil, nil
+}
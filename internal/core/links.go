@@ -0,0 +1,107 @@
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// shareLinkPrefix starts every share link. The paste is fetched from the
+// reader's own server.
+const shareLinkPrefix = "pastepal://paste/"
+
+// ErrInvalidShareLink is returned for text that isn't a share link
+var ErrInvalidShareLink = errors.New("not a PastePal share link")
+
+// ShareLink returns a link that opens one of the user's public pastes. The
+// paste's key is in the link's fragment, which is never sent to the server,
+// so anyone holding the link can read the paste and the link must be
+// treated as a secret.
+func (app *PastePalApp) ShareLink(pasteID string) (string, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return "", err
+	}
+
+	paste, err := app.APIClient.GetPaste(pasteID)
+	if err != nil {
+		return "", err
+	}
+
+	return app.shareLink(paste)
+}
+
+// shareLink returns the share link of a paste. Callers must hold the lock.
+func (app *PastePalApp) shareLink(paste *models.Paste) (string, error) {
+	if paste.UserID != app.CurrentUser.ID {
+		return "", errors.New("only your own pastes can be linked to")
+	}
+	if !paste.IsPublic {
+		return "", errors.New("only public pastes can be opened from a link")
+	}
+
+	// Older pastes use the symmetric key, which must never leave the device
+	var err error
+	if paste.EncryptionVersion == models.PasteEncryptionLegacy {
+		paste, err = app.upgradePaste(paste)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	contentKey, err := app.contentKey(paste)
+	if err != nil {
+		return "", err
+	}
+
+	return shareLinkPrefix + paste.ID + "#" + base64.RawURLEncoding.EncodeToString(contentKey), nil
+}
+
+// OpenShareLink fetches and decrypts the paste a share link points to. It
+// doesn't need the vault to be unlocked.
+func (app *PastePalApp) OpenShareLink(link string) (*PasteView, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(link), shareLinkPrefix)
+	if !ok {
+		return nil, ErrInvalidShareLink
+	}
+
+	pasteID, encodedKey, ok := strings.Cut(rest, "#")
+	if !ok || pasteID == "" || strings.ContainsAny(pasteID, "/?") {
+		return nil, ErrInvalidShareLink
+	}
+
+	contentKey, err := base64.RawURLEncoding.DecodeString(encodedKey)
+	if err != nil || len(contentKey) != 32 {
+		return nil, ErrInvalidShareLink
+	}
+
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	paste, err := app.APIClient.GetPaste(pasteID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Links are only made for pastes with a key of their own
+	if paste.EncryptionVersion == models.PasteEncryptionLegacy {
+		return nil, errors.New("linked paste uses an unsupported encryption version")
+	}
+
+	view, err := decryptPasteView(paste, contentKey)
+	if err != nil {
+		return nil, errors.New("failed to decrypt the linked paste: the link is incomplete or the paste has changed")
+	}
+
+	if app.CurrentUser != nil && paste.UserID == app.CurrentUser.ID {
+		view.Author = verifyPaste(paste, app.CurrentUser.Email, app.CurrentUser.SigningPublicKey)
+	} else {
+		view.Author = verifyPaste(paste, "", "")
+	}
+
+	return view, nil
+}
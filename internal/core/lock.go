@@ -0,0 +1,176 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/auth"
+)
+
+// ErrLocked is returned by operations that need the user's keys while the
+// vault is locked
+var ErrLocked = errors.New("vault is locked")
+
+// autoLockInterval is how often inactivity is checked
+const autoLockInterval = 10 * time.Second
+
+// requireKeys checks that the user's keys are available and counts the call
+// as activity. Callers must hold the lock.
+func (app *PastePalApp) requireKeys() error {
+	if !app.IsLoggedIn {
+		return errors.New("not logged in")
+	}
+	if app.locked {
+		return ErrLocked
+	}
+
+	app.RecordActivity()
+	return nil
+}
+
+// RecordActivity marks the user as active, postponing the idle auto-lock
+func (app *PastePalApp) RecordActivity() {
+	app.lastActivity.Store(time.Now().UnixNano())
+}
+
+// SetLockHandler sets the function called after the vault has been locked
+func (app *PastePalApp) SetLockHandler(handler func()) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	app.lockHandler = handler
+}
+
+// IsLocked reports whether the vault is locked
+func (app *PastePalApp) IsLocked() bool {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	return app.locked
+}
+
+// Lock wipes all key material from memory while keeping the session, so the
+// vault can be unlocked again with the master password alone
+func (app *PastePalApp) Lock() {
+	app.mutex.Lock()
+	if !app.IsLoggedIn || app.locked {
+		app.mutex.Unlock()
+		return
+	}
+
+	app.haltTrashPurge()
+	app.keys.Wipe()
+	app.dropSearchIndex()
+	app.locked = true
+	handler := app.lockHandler
+	app.mutex.Unlock()
+
+	fmt.Println("[Core] Vault locked")
+
+	// Call the handler outside the lock so it can use the app
+	if handler != nil {
+		handler()
+	}
+}
+
+// Unlock re-derives the keys from the master password. It doesn't contact
+// the server; the encrypted keys from login are still in memory.
+func (app *PastePalApp) Unlock(password string) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if !app.IsLoggedIn {
+		return errors.New("not logged in")
+	}
+	if !app.locked {
+		return nil
+	}
+
+	symmetricKey, err := auth.LoginUser(app.CurrentUser.Email, password, app.CurrentUser.EncryptedSymmetricKey)
+	if err != nil {
+		return errors.New("incorrect master password")
+	}
+
+	app.unlockWith(symmetricKey)
+	return nil
+}
+
+// unlockWith takes over the symmetric key and unlocks the rest of the keys
+// with it. Callers must hold the write lock.
+func (app *PastePalApp) unlockWith(symmetricKey []byte) {
+	app.keys.SetSymmetricKey(symmetricKey)
+
+	if app.keyPair != nil {
+		if err := app.unlockKeyPair(app.keyPair); err != nil {
+			fmt.Printf("[Core] Sharing is unavailable: %v\n", err)
+		}
+	}
+
+	app.locked = false
+	app.RecordActivity()
+	app.startTrashPurge()
+
+	// Pick up settings changed on another device while locked
+	if err := app.loadAccountSettings(); err != nil {
+		fmt.Printf("[Core] Failed to refresh account settings: %v\n", err)
+	}
+}
+
+// StartAutoLock locks the vault after the configured idle time and, where
+// the platform reports it, when the screen is locked
+func (app *PastePalApp) StartAutoLock() error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if app.stopAutoLock != nil {
+		return nil
+	}
+
+	app.RecordActivity()
+
+	done := make(chan struct{})
+	go app.runIdleLock(done)
+
+	stopScreenLock, err := watchScreenLock(func() {
+		if app.GetConfig().LockOnScreenLock {
+			app.Lock()
+		}
+	})
+
+	app.stopAutoLock = func() {
+		close(done)
+		if stopScreenLock != nil {
+			stopScreenLock()
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("screen lock detection unavailable: %v", err)
+	}
+	return nil
+}
+
+// runIdleLock locks the vault once the user has been idle for the
+// configured number of minutes
+func (app *PastePalApp) runIdleLock(done <-chan struct{}) {
+	ticker := time.NewTicker(autoLockInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			minutes := app.GetConfig().AutoLockMinutes
+			if minutes <= 0 {
+				continue
+			}
+
+			idle := time.Since(time.Unix(0, app.lastActivity.Load()))
+			if idle >= time.Duration(minutes)*time.Minute {
+				app.Lock()
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package core
+
+import "syscall"
+
+// allocLocked maps anonymous memory for a secret and tries to lock it into
+// RAM. Locking fails quietly when RLIMIT_MEMLOCK is exhausted; the memory is
+// still kept off the Go heap.
+func allocLocked(size int) ([]byte, bool, func()) {
+	data, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return make([]byte, size), false, func() {}
+	}
+
+	locked := syscall.Mlock(data) == nil
+	return data, locked, func() {
+		if locked {
+			syscall.Munlock(data)
+		}
+		syscall.Munmap(data)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package core
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// allocLocked allocates memory for a secret with VirtualAlloc and tries to
+// lock it into RAM with VirtualLock
+func allocLocked(size int) ([]byte, bool, func()) {
+	addr, err := windows.VirtualAlloc(0, uintptr(size), windows.MEM_COMMIT|windows.MEM_RESERVE, windows.PAGE_READWRITE)
+	if err != nil {
+		return make([]byte, size), false, func() {}
+	}
+
+	// The memory is outside the Go heap, so converting the address is safe
+	data := unsafe.Slice((*byte)(unsafe.Add(nil, addr)), size)
+	locked := windows.VirtualLock(addr, uintptr(size)) == nil
+	return data, locked, func() {
+		if locked {
+			windows.VirtualUnlock(addr, uintptr(size))
+		}
+		windows.VirtualFree(addr, 0, windows.MEM_RELEASE)
+	}
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+	"github.com/JacobRWebb/PastePal-OS/internal/storage"
+)
+
+var (
+	// ErrPINInvalidated is returned once too many wrong PINs were entered
+	ErrPINInvalidated = errors.New("too many wrong PINs; unlock with your master password")
+	// ErrPINExpired is returned once the PIN is older than the PIN lifetime
+	ErrPINExpired = errors.New("PIN has expired; unlock with your master password")
+)
+
+// PIN length limits, in digits
+const (
+	minPINLength = 4
+	maxPINLength = 12
+)
+
+// validatePIN checks that a PIN is all digits and of a sensible length
+func validatePIN(pin string) error {
+	if len(pin) < minPINLength || len(pin) > maxPINLength {
+		return fmt.Errorf("PIN must be %d to %d digits", minPINLength, maxPINLength)
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return errors.New("PIN must only contain digits")
+		}
+	}
+	return nil
+}
+
+// pinAAD binds a PIN-wrapped key to the user it belongs to
+func pinAAD(userID string) []byte {
+	return lengthPrefixed("pastepal-pin-v1", userID)
+}
+
+// SetupPIN enables quick unlock with a PIN by storing the symmetric key on
+// this device, wrapped with a key derived from the PIN
+func (app *PastePalApp) SetupPIN(pin string) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+
+	if err := validatePIN(pin); err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	pinKey := crypto.DeriveKeyFromPIN(pin, salt, crypto.PINArgonTime, crypto.PINArgonMemory, crypto.PINArgonThreads)
+	defer clear(pinKey)
+
+	wrappedKey, err := crypto.EncryptDataWithAAD(app.keys.SymmetricKey(), pinKey, pinAAD(app.CurrentUser.ID))
+	if err != nil {
+		return err
+	}
+
+	return app.LocalStorage.SavePIN(&storage.PINRecord{
+		UserID:     app.CurrentUser.ID,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		WrappedKey: wrappedKey,
+		Time:       crypto.PINArgonTime,
+		Memory:     crypto.PINArgonMemory,
+		Threads:    crypto.PINArgonThreads,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// RemovePIN disables quick unlock and deletes the wrapped key
+func (app *PastePalApp) RemovePIN() error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	return app.LocalStorage.DeletePIN()
+}
+
+// HasPIN reports whether the current user can unlock with a PIN
+func (app *PastePalApp) HasPIN() bool {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn {
+		return false
+	}
+
+	record, err := app.LocalStorage.GetPIN()
+	if err != nil || record.UserID != app.CurrentUser.ID {
+		return false
+	}
+
+	return app.pinUsable(record) == nil
+}
+
+// pinUsable checks a PIN record against the configured limits
+func (app *PastePalApp) pinUsable(record *storage.PINRecord) error {
+	cfg := app.GetConfig()
+
+	lifetime := time.Duration(cfg.PINLifetimeHours) * time.Hour
+	if time.Since(record.CreatedAt) > lifetime {
+		return ErrPINExpired
+	}
+
+	if record.FailedAttempts >= cfg.PINMaxAttempts {
+		return ErrPINInvalidated
+	}
+
+	return nil
+}
+
+// UnlockWithPIN unlocks the vault with the PIN instead of the master
+// password. Too many wrong PINs, or not entering the master password for
+// too long, delete the PIN so that only the master password works.
+func (app *PastePalApp) UnlockWithPIN(pin string) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if !app.IsLoggedIn {
+		return errors.New("not logged in")
+	}
+	if !app.locked {
+		return nil
+	}
+
+	record, err := app.LocalStorage.GetPIN()
+	if err != nil || record.UserID != app.CurrentUser.ID {
+		return storage.ErrNoPIN
+	}
+
+	if err := app.pinUsable(record); err != nil {
+		app.LocalStorage.DeletePIN()
+		return err
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(record.Salt)
+	if err != nil {
+		return err
+	}
+
+	pinKey := crypto.DeriveKeyFromPIN(pin, salt, record.Time, record.Memory, record.Threads)
+	defer clear(pinKey)
+
+	symmetricKey, err := crypto.DecryptDataWithAAD(record.WrappedKey, pinKey, pinAAD(app.CurrentUser.ID))
+	if err != nil {
+		record.FailedAttempts++
+
+		remaining := app.GetConfig().PINMaxAttempts - record.FailedAttempts
+		if remaining <= 0 {
+			app.LocalStorage.DeletePIN()
+			return ErrPINInvalidated
+		}
+
+		if err := app.LocalStorage.SavePIN(record); err != nil {
+			return err
+		}
+		return fmt.Errorf("incorrect PIN, %d attempts left", remaining)
+	}
+
+	if record.FailedAttempts > 0 {
+		record.FailedAttempts = 0
+		if err := app.LocalStorage.SavePIN(record); err != nil {
+			clear(symmetricKey)
+			return err
+		}
+	}
+
+	app.unlockWith(symmetricKey)
+	return nil
+}
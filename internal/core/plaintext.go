@@ -0,0 +1,291 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+	"github.com/JacobRWebb/PastePal-OS/internal/syntax"
+)
+
+// PlaintextFormat is how a plaintext export is laid out
+type PlaintextFormat string
+
+const (
+	// PlaintextDirectory writes a directory with a file per paste, named
+	// after its title with an extension for its language
+	PlaintextDirectory PlaintextFormat = "dir"
+	// PlaintextJSON writes a single JSON document
+	PlaintextJSON PlaintextFormat = "json"
+	// PlaintextMarkdown writes a single Markdown file with every paste
+	PlaintextMarkdown PlaintextFormat = "markdown"
+)
+
+// ErrPlaintextNotConfirmed is returned by plaintext exports that weren't
+// explicitly confirmed
+var ErrPlaintextNotConfirmed = errors.New("plaintext export not confirmed: it writes your pastes to disk unencrypted")
+
+// Longest file name, without extension, of a paste in a directory export
+const maxExportNameLength = 80
+
+// PlaintextExportOptions controls a plaintext export
+type PlaintextExportOptions struct {
+	Format PlaintextFormat
+	Filter PasteFilter
+	// Confirmed acknowledges that the export is unencrypted and readable by
+	// anything with access to it. Exports are refused without it.
+	Confirmed bool
+}
+
+// PlaintextPaste is a decrypted paste as written by a plaintext export
+type PlaintextPaste struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Language  string    `json:"language,omitempty"`
+	Format    string    `json:"format"`
+	Tags      []string  `json:"tags,omitempty"`
+	Folder    string    `json:"folder,omitempty"`
+	IsPublic  bool      `json:"is_public"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// ExportPlaintext decrypts the user's pastes matching the filter and writes
+// them unencrypted to path, which must not exist yet. It returns how many
+// pastes were written.
+func (app *PastePalApp) ExportPlaintext(path string, opts PlaintextExportOptions) (int, error) {
+	if !opts.Confirmed {
+		return 0, ErrPlaintextNotConfirmed
+	}
+
+	var write func(string, []*PlaintextPaste) error
+	switch opts.Format {
+	case PlaintextDirectory:
+		write = writePlaintextDirectory
+	case PlaintextJSON:
+		write = writePlaintextJSON
+	case PlaintextMarkdown:
+		write = writePlaintextMarkdown
+	default:
+		return 0, fmt.Errorf("unknown plaintext export format %q", opts.Format)
+	}
+
+	pastes, err := app.decryptForExport(opts.Filter)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := write(path, pastes); err != nil {
+		return 0, err
+	}
+
+	fmt.Printf("[Core] Exported %d pastes unencrypted as %s\n", len(pastes), opts.Format)
+	return len(pastes), nil
+}
+
+// decryptForExport decrypts the user's pastes matching the filter, oldest
+// first
+func (app *PastePalApp) decryptForExport(filter PasteFilter) ([]*PlaintextPaste, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	pastes, err := app.APIClient.GetUserPastes()
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := app.getFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	pastes, err = app.filterPastes(pastes, folders, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*PlaintextPaste
+	for _, paste := range pastes {
+		contentKey, err := app.contentKey(paste)
+		if err != nil {
+			return nil, fmt.Errorf("paste %s: %v", paste.ID, err)
+		}
+
+		view, err := decryptPasteView(paste, contentKey)
+		if err != nil {
+			return nil, fmt.Errorf("paste %s: %v", paste.ID, err)
+		}
+
+		tags, err := app.decryptTags(paste)
+		if err != nil {
+			return nil, fmt.Errorf("paste %s: %v", paste.ID, err)
+		}
+
+		result = append(result, &PlaintextPaste{
+			ID:        paste.ID,
+			Title:     view.Title,
+			Content:   view.Content,
+			Language:  view.Language,
+			Format:    view.Format,
+			Tags:      tags,
+			Folder:    FolderPath(folders, paste.FolderID),
+			IsPublic:  paste.IsPublic,
+			CreatedAt: paste.CreatedAt,
+			ExpiresAt: paste.ExpiresAt,
+		})
+	}
+
+	// Oldest first, so exports of the same pastes come out the same
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+// writePlaintextDirectory creates a directory with a file for each paste.
+// The directory is removed again when a file can't be written.
+func writePlaintextDirectory(path string, pastes []*PlaintextPaste) error {
+	if err := os.Mkdir(path, 0700); err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	for _, paste := range pastes {
+		name := exportFileName(paste, used)
+		if err := writeNewFile(filepath.Join(path, name), []byte(paste.Content)); err != nil {
+			os.RemoveAll(path)
+			return err
+		}
+	}
+	return nil
+}
+
+// exportFileName names a paste's file after its title, with an extension
+// for its language, numbering names that are already used
+func exportFileName(paste *PlaintextPaste, used map[string]bool) string {
+	base := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, paste.Title)
+	base = strings.Trim(base, " .")
+	if runes := []rune(base); len(runes) > maxExportNameLength {
+		base = strings.TrimRight(string(runes[:maxExportNameLength]), " .")
+	}
+	if base == "" {
+		base = "untitled"
+	}
+
+	ext := syntax.Extension(paste.Language)
+	if paste.Format == models.PasteFormatMarkdown {
+		ext = ".md"
+	} else if paste.Format == models.PasteFormatPlain {
+		ext = ".txt"
+	}
+
+	// Names are compared ignoring case for file systems that do
+	name := base + ext
+	for n := 2; used[strings.ToLower(name)]; n++ {
+		name = fmt.Sprintf("%s (%d)%s", base, n, ext)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// writePlaintextJSON writes the pastes as a single JSON document
+func writePlaintextJSON(path string, pastes []*PlaintextPaste) error {
+	document := struct {
+		ExportedAt time.Time         `json:"exported_at"`
+		Pastes     []*PlaintextPaste `json:"pastes"`
+	}{
+		ExportedAt: time.Now().UTC(),
+		Pastes:     pastes,
+	}
+	if document.Pastes == nil {
+		document.Pastes = []*PlaintextPaste{}
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeNewFile(path, append(data, '\n'))
+}
+
+// writePlaintextMarkdown writes the pastes as a single Markdown file. Code
+// and plain text are fenced, and Markdown pastes are included as they are.
+func writePlaintextMarkdown(path string, pastes []*PlaintextPaste) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# PastePal export\n\nExported %s. %d pastes.\n", time.Now().UTC().Format(time.RFC1123), len(pastes))
+
+	for _, paste := range pastes {
+		title := strings.Join(strings.Fields(paste.Title), " ")
+		if title == "" {
+			title = "Untitled"
+		}
+		fmt.Fprintf(&b, "\n---\n\n## %s\n\n", title)
+
+		fmt.Fprintf(&b, "- Created: %s\n", paste.CreatedAt.Format(time.RFC1123))
+		if paste.Folder != "" {
+			fmt.Fprintf(&b, "- Folder: %s\n", paste.Folder)
+		}
+		if len(paste.Tags) > 0 {
+			fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(paste.Tags, ", "))
+		}
+		if paste.Language != "" && paste.Language != syntax.PlainText {
+			fmt.Fprintf(&b, "- Language: %s\n", paste.Language)
+		}
+		b.WriteString("\n")
+
+		if paste.Format == models.PasteFormatMarkdown {
+			b.WriteString(strings.TrimRight(paste.Content, "\n"))
+			b.WriteString("\n")
+			continue
+		}
+
+		// The fence must be longer than any run of backticks in the content
+		fence := "```"
+		for strings.Contains(paste.Content, fence) {
+			fence += "`"
+		}
+		info := ""
+		if paste.Format == models.PasteFormatCode {
+			info = strings.ToLower(strings.TrimPrefix(syntax.Extension(paste.Language), "."))
+		}
+		fmt.Fprintf(&b, "%s%s\n%s\n%s\n", fence, info, strings.TrimRight(paste.Content, "\n"), fence)
+	}
+
+	return writeNewFile(path, []byte(b.String()))
+}
+
+// writeNewFile writes a file readable only by the user, refusing to replace
+// one that exists
+func writeNewFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
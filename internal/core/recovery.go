@@ -0,0 +1,61 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/auth"
+)
+
+// SetupRecoveryKey creates a new recovery key for the logged in user,
+// replacing any previous one, and returns its mnemonic
+func (app *PastePalApp) SetupRecoveryKey() (string, error) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if err := app.requireKeys(); err != nil {
+		return "", err
+	}
+
+	recoveryData, mnemonic, err := auth.CreateRecoveryKey(app.keys.SymmetricKey())
+	if err != nil {
+		return "", err
+	}
+
+	if err := app.APIClient.SetRecoveryKey(recoveryData); err != nil {
+		return "", err
+	}
+
+	app.CurrentUser.HasRecoveryKey = true
+	return mnemonic, nil
+}
+
+// RecoverAccount sets a new master password using the recovery key mnemonic.
+// The symmetric key is unchanged, so every existing paste stays readable.
+// twoFactorCode is required for accounts with two-factor authentication.
+func (app *PastePalApp) RecoverAccount(email, mnemonic, newPassword, twoFactorCode string) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	recoveryReq, recoveryKey, err := auth.PrepareRecoveryRequest(email, mnemonic)
+	if err != nil {
+		return err
+	}
+	recoveryReq.Code = strings.TrimSpace(twoFactorCode)
+
+	useSRP, err := app.APIClient.SupportsSRP()
+	if err != nil {
+		return err
+	}
+
+	recoveryResp, err := app.APIClient.StartRecovery(recoveryReq)
+	if err != nil {
+		return err
+	}
+
+	resetReq, err := auth.ResetPasswordWithRecoveryKey(email, newPassword, recoveryKey, recoveryResp, useSRP)
+	if err != nil {
+		return err
+	}
+
+	return app.APIClient.CompleteRecovery(resetReq)
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/secrets"
+)
+
+// RedactPaste replaces the likely secrets in the title and content of one
+// of the user's pastes, so that a share link made afterwards doesn't reveal
+// them. It returns how many were redacted.
+func (app *PastePalApp) RedactPaste(pasteID string) (int, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return 0, err
+	}
+
+	paste, err := app.APIClient.GetPaste(pasteID)
+	if err != nil {
+		return 0, err
+	}
+	if paste.UserID != app.CurrentUser.ID {
+		return 0, errors.New("only your own pastes can be redacted")
+	}
+
+	contentKey, err := app.contentKey(paste)
+	if err != nil {
+		return 0, err
+	}
+
+	view, err := decryptPasteView(paste, contentKey)
+	if err != nil {
+		return 0, err
+	}
+
+	titleFindings := secrets.Scan(view.Title)
+	contentFindings := secrets.Scan(view.Content)
+	count := len(titleFindings) + len(contentFindings)
+	if count == 0 {
+		return 0, nil
+	}
+	view.Title = secrets.Redact(view.Title, titleFindings)
+	view.Content = secrets.Redact(view.Content, contentFindings)
+
+	// Drop the unredacted text from the search index first, so it is gone
+	// even if writing the paste back fails part way
+	app.unindexPaste(pasteID)
+
+	updated, err := app.rewritePaste(paste, contentKey, view, paste.Signature != "", false)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := app.LocalStorage.SavePasteLocally(updated); err != nil {
+		fmt.Printf("[Core] Failed to cache redacted paste %s: %v\n", pasteID, err)
+	}
+
+	fmt.Printf("[Core] Redacted %d likely secrets in paste %s\n", count, pasteID)
+	return count, nil
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/config"
+)
+
+// ConfigListener is notified after a new configuration has been applied
+type ConfigListener func(oldConfig, newConfig *config.Config)
+
+// SubscribeConfig registers a listener for live config changes and returns a
+// function that removes it again
+func (app *PastePalApp) SubscribeConfig(listener ConfigListener) func() {
+	app.configMutex.Lock()
+	defer app.configMutex.Unlock()
+
+	id := app.nextListenerID
+	app.nextListenerID++
+	app.configListeners[id] = listener
+
+	return func() {
+		app.configMutex.Lock()
+		defer app.configMutex.Unlock()
+
+		delete(app.configListeners, id)
+	}
+}
+
+// GetConfig returns a copy of the current configuration
+func (app *PastePalApp) GetConfig() config.Config {
+	app.configMutex.RLock()
+	defer app.configMutex.RUnlock()
+
+	return *app.Config
+}
+
+// StartConfigWatcher begins watching the config file and applying changes live
+func (app *PastePalApp) StartConfigWatcher() error {
+	app.configMutex.Lock()
+	defer app.configMutex.Unlock()
+
+	if app.configWatcher != nil {
+		return nil
+	}
+
+	watcher, err := config.NewWatcher(app.configPath, app.ApplyConfig, func(err error) {
+		fmt.Printf("[Core] Ignoring invalid config change: %v\n", err)
+	})
+	if err != nil {
+		return err
+	}
+
+	app.configWatcher = watcher
+	return nil
+}
+
+// Close stops background work started by the application and wipes any keys
+// still in memory
+func (app *PastePalApp) Close() error {
+	app.mutex.Lock()
+	if app.stopAutoLock != nil {
+		app.stopAutoLock()
+		app.stopAutoLock = nil
+	}
+	app.purgeTrash = false
+	app.haltTrashPurge()
+	app.keys.Wipe()
+	app.dropSearchIndex()
+	app.mutex.Unlock()
+
+	app.configMutex.Lock()
+	defer app.configMutex.Unlock()
+
+	if app.configWatcher == nil {
+		return nil
+	}
+
+	err := app.configWatcher.Close()
+	app.configWatcher = nil
+	return err
+}
+
+// ApplyConfig validates a new configuration, applies the settings that are
+// safe to change at runtime and notifies subscribers
+func (app *PastePalApp) ApplyConfig(newConfig *config.Config) {
+	if err := newConfig.Validate(); err != nil {
+		fmt.Printf("[Core] Ignoring invalid config change: %v\n", err)
+		return
+	}
+
+	// Logins take the write lock, so none can finish between deciding on the
+	// server below and pointing the API client at it
+	app.mutex.RLock()
+	loggedIn := app.IsLoggedIn
+
+	app.configMutex.Lock()
+	oldConfig := app.Config
+
+	// The storage location can't move while the app holds files open in it
+	applied := *newConfig
+	if applied.StoragePath != oldConfig.StoragePath {
+		fmt.Println("[Core] storage_path changes take effect after a restart")
+		applied.StoragePath = oldConfig.StoragePath
+	}
+
+	// The session's auth token must never reach another server, so the
+	// server only changes once the user has logged out
+	app.pendingAPIURL = ""
+	if applied.APIURL != oldConfig.APIURL && loggedIn {
+		fmt.Println("[Core] api_url changes take effect after logging out")
+		app.pendingAPIURL = applied.APIURL
+		applied.APIURL = oldConfig.APIURL
+	}
+
+	app.Config = &applied
+	app.applyConnectionSettings(oldConfig, &applied)
+
+	listeners := make([]ConfigListener, 0, len(app.configListeners))
+	for _, listener := range app.configListeners {
+		listeners = append(listeners, listener)
+	}
+	app.configMutex.Unlock()
+	app.mutex.RUnlock()
+
+	// Notify outside the lock so listeners may call back into the app
+	for _, listener := range listeners {
+		listener(oldConfig, &applied)
+	}
+}
+
+// applyConnectionSettings points the API client at the configured server
+// and rebuilds its connection when any connection setting changed. Callers
+// must hold the lock.
+func (app *PastePalApp) applyConnectionSettings(oldConfig, newConfig *config.Config) {
+	if newConfig.APIURL != oldConfig.APIURL ||
+		newConfig.RequestTimeout != oldConfig.RequestTimeout ||
+		!reflect.DeepEqual(newConfig.TLS, oldConfig.TLS) ||
+		!reflect.DeepEqual(newConfig.Proxy, oldConfig.Proxy) {
+		if err := app.APIClient.Configure(newConfig); err != nil {
+			fmt.Printf("[Core] Failed to apply connection settings: %v\n", err)
+		}
+	}
+}
+
+// applyConfigToServices pushes runtime settings into the cache
+func (app *PastePalApp) applyConfigToServices(oldConfig, newConfig *config.Config) {
+	if newConfig.CacheMaxPastes != oldConfig.CacheMaxPastes {
+		if err := app.LocalStorage.SetCacheLimit(newConfig.CacheMaxPastes); err != nil {
+			fmt.Printf("[Core] Failed to apply cache limit: %v\n", err)
+		}
+	}
+}
+
+// applyPendingAPIURL switches to the server that was configured while the
+// user was logged in
+func (app *PastePalApp) applyPendingAPIURL() {
+	app.configMutex.RLock()
+	pending := *app.Config
+	pending.APIURL = app.pendingAPIURL
+	app.configMutex.RUnlock()
+
+	if pending.APIURL != "" {
+		app.ApplyConfig(&pending)
+	}
+}
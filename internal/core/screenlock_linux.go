@@ -0,0 +1,52 @@
+//go:build linux
+
+package core
+
+import (
+	"github.com/godbus/dbus/v5"
+)
+
+// screenSaverInterfaces are the D-Bus interfaces desktops announce screen
+// locking on
+var screenSaverInterfaces = []string{
+	"org.freedesktop.ScreenSaver",
+	"org.gnome.ScreenSaver",
+	"org.mate.ScreenSaver",
+	"org.cinnamon.ScreenSaver",
+}
+
+// watchScreenLock calls onLock whenever the desktop's screen saver becomes
+// active, and returns a function that stops watching
+func watchScreenLock(onLock func()) (func(), error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, iface := range screenSaverInterfaces {
+		if err := conn.AddMatchSignal(dbus.WithMatchInterface(iface), dbus.WithMatchMember("ActiveChanged")); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	signals := make(chan *dbus.Signal, 8)
+	conn.Signal(signals)
+
+	go func() {
+		for signal := range signals {
+			if len(signal.Body) == 0 {
+				continue
+			}
+			if active, ok := signal.Body[0].(bool); ok && active {
+				onLock()
+			}
+		}
+	}()
+
+	return func() {
+		conn.RemoveSignal(signals)
+		close(signals)
+		conn.Close()
+	}, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package core
+
+import "errors"
+
+// watchScreenLock is only implemented for desktops reachable over D-Bus
+func watchScreenLock(onLock func()) (func(), error) {
+	return nil, errors.New("not supported on this platform")
+}
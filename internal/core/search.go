@@ -0,0 +1,236 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+	"github.com/JacobRWebb/PastePal-OS/internal/search"
+)
+
+// searchIndexAAD binds the encrypted search index to the user it belongs to
+func searchIndexAAD(userID string) []byte {
+	return lengthPrefixed("pastepal-search-index-v1", userID)
+}
+
+// unindexPaste drops a paste from the search index, so its decrypted text
+// doesn't outlive it there. Failures are logged like indexPaste's. Callers
+// must hold the app lock with the keys available.
+func (app *PastePalApp) unindexPaste(pasteID string) {
+	app.searchMutex.Lock()
+	defer app.searchMutex.Unlock()
+
+	idx, err := app.loadSearchIndex()
+	if err != nil {
+		fmt.Printf("[Core] Failed to load search index: %v\n", err)
+		return
+	}
+
+	if _, ok := idx.Version(pasteID); !ok {
+		return
+	}
+	idx.Remove(pasteID)
+
+	if err := app.saveSearchIndex(idx); err != nil {
+		fmt.Printf("[Core] Failed to save search index: %v\n", err)
+	}
+}
+
+// pruneSearchIndex drops the pastes that aren't in known from the search
+// index. Callers must hold the app lock with the keys available.
+func (app *PastePalApp) pruneSearchIndex(known map[string]bool) {
+	app.searchMutex.Lock()
+	defer app.searchMutex.Unlock()
+
+	idx, err := app.loadSearchIndex()
+	if err != nil {
+		fmt.Printf("[Core] Failed to load search index: %v\n", err)
+		return
+	}
+
+	changed := false
+	for _, id := range idx.IDs() {
+		if !known[id] {
+			idx.Remove(id)
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if err := app.saveSearchIndex(idx); err != nil {
+		fmt.Printf("[Core] Failed to save search index: %v\n", err)
+	}
+}
+
+// pasteVersion hashes the encrypted title and content of a paste. Any edit
+// re-encrypts them, so the hash changes whenever the indexed text could.
+func pasteVersion(paste *models.Paste) string {
+	sum := sha256.Sum256(lengthPrefixed(paste.Title, paste.Content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Search looks through the decrypted titles and content of the user's
+// pastes. The index is kept on this device, since the server only ever sees
+// ciphertext.
+func (app *PastePalApp) Search(query string, mode search.Mode) ([]search.Result, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	app.searchMutex.Lock()
+	defer app.searchMutex.Unlock()
+
+	idx, err := app.loadSearchIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	return idx.Search(query, mode)
+}
+
+// loadSearchIndex returns the user's search index, decrypting it from disk
+// the first time. Callers must hold the app lock and app.searchMutex.
+func (app *PastePalApp) loadSearchIndex() (*search.Index, error) {
+	if app.searchIndex != nil {
+		return app.searchIndex, nil
+	}
+
+	encrypted, err := app.LocalStorage.GetSearchIndex(app.CurrentUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := search.NewIndex()
+	if encrypted != "" {
+		data, err := crypto.DecryptEnvelope(encrypted, app.keys.SymmetricKey(), searchIndexAAD(app.CurrentUser.ID))
+		if err == nil {
+			idx, err = search.Load(data)
+			clear(data)
+		}
+		if err != nil {
+			// The index only mirrors the pastes, so it is rebuilt from them
+			fmt.Printf("[Core] Rebuilding unreadable search index: %v\n", err)
+			idx = search.NewIndex()
+		}
+	}
+
+	app.searchIndex = idx
+	return idx, nil
+}
+
+// saveSearchIndex encrypts the search index under the account key and
+// stores it. Callers must hold the app lock and app.searchMutex.
+func (app *PastePalApp) saveSearchIndex(idx *search.Index) error {
+	data, err := idx.Marshal()
+	if err != nil {
+		return err
+	}
+	defer clear(data)
+
+	encrypted, err := crypto.EncryptEnvelope(data, app.keys.SymmetricKey(), searchIndexAAD(app.CurrentUser.ID), app.pasteEnvelope(data, false))
+	if err != nil {
+		return err
+	}
+
+	return app.LocalStorage.SaveSearchIndex(app.CurrentUser.ID, encrypted)
+}
+
+// indexPaste adds or updates a paste in the search index. Failures only
+// cost search results, so they are logged rather than returned.
+func (app *PastePalApp) indexPaste(paste *models.Paste, title, content string) {
+	app.searchMutex.Lock()
+	defer app.searchMutex.Unlock()
+
+	idx, err := app.loadSearchIndex()
+	if err != nil {
+		fmt.Printf("[Core] Failed to load search index: %v\n", err)
+		return
+	}
+
+	idx.Put(paste.ID, &search.Document{
+		Title:     title,
+		Content:   content,
+		CreatedAt: paste.CreatedAt,
+		Version:   pasteVersion(paste),
+	})
+
+	if err := app.saveSearchIndex(idx); err != nil {
+		fmt.Printf("[Core] Failed to save search index: %v\n", err)
+	}
+}
+
+// syncSearchIndex brings the search index in line with the user's pastes on
+// the server. Pastes it is missing or that changed since they were indexed,
+// such as after a redaction on another device, are decrypted and added, and
+// pastes no longer on the server are dropped.
+func (app *PastePalApp) syncSearchIndex(pastes []*models.Paste) error {
+	app.searchMutex.Lock()
+	defer app.searchMutex.Unlock()
+
+	idx, err := app.loadSearchIndex()
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	present := make(map[string]bool, len(pastes))
+	for _, paste := range pastes {
+		present[paste.ID] = true
+		version := pasteVersion(paste)
+		indexed, ok := idx.Version(paste.ID)
+		if ok && indexed == version {
+			continue
+		}
+
+		contentKey, err := app.contentKey(paste)
+		var title, content string
+		if err == nil {
+			title, content, err = decryptPaste(paste, contentKey)
+		}
+		if err != nil {
+			fmt.Printf("[Core] Not indexing paste %s: %v\n", paste.ID, err)
+			// Text from an earlier version may have since been redacted
+			if ok {
+				idx.Remove(paste.ID)
+				changed = true
+			}
+			continue
+		}
+
+		idx.Put(paste.ID, &search.Document{
+			Title:     title,
+			Content:   content,
+			CreatedAt: paste.CreatedAt,
+			Version:   version,
+		})
+		changed = true
+	}
+
+	for _, id := range idx.IDs() {
+		if !present[id] {
+			idx.Remove(id)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return app.saveSearchIndex(idx)
+}
+
+// dropSearchIndex forgets the decrypted index held in memory. The encrypted
+// copy on disk is kept.
+func (app *PastePalApp) dropSearchIndex() {
+	app.searchMutex.Lock()
+	defer app.searchMutex.Unlock()
+
+	app.searchIndex = nil
+}
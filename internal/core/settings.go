@@ -0,0 +1,139 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+)
+
+// settingsAADDomain separates account settings associated data from any
+// other use of the symmetric key
+const settingsAADDomain = "pastepal-settings-aad-v1"
+
+// Supported padding schemes for encrypted pastes
+const (
+	PaddingPadme      = "padme"
+	PaddingPowerOfTwo = "power_of_two"
+	PaddingOff        = "off"
+)
+
+// AccountSettings are settings kept with the user's account rather than on
+// one device, so all of the user's devices write data the same way and the
+// server can't tell them apart by it
+type AccountSettings struct {
+	// Padding hides the length of encrypted data, one of the Padding values
+	Padding string `json:"padding"`
+	// TrashRetentionDays is how long deleted pastes stay in the trash
+	// before any of the user's devices purges them
+	TrashRetentionDays int `json:"trash_retention_days"`
+}
+
+// DefaultAccountSettings returns the settings of accounts that haven't
+// saved any
+func DefaultAccountSettings() AccountSettings {
+	return AccountSettings{
+		Padding:            PaddingPadme,
+		TrashRetentionDays: 30,
+	}
+}
+
+// validate checks that the settings are usable
+func (s *AccountSettings) validate() error {
+	switch s.Padding {
+	case PaddingPadme, PaddingPowerOfTwo, PaddingOff:
+	default:
+		return fmt.Errorf("invalid padding %q: expected %q, %q or %q", s.Padding, PaddingPadme, PaddingPowerOfTwo, PaddingOff)
+	}
+
+	if s.TrashRetentionDays <= 0 {
+		return errors.New("trash retention must be at least one day")
+	}
+
+	return nil
+}
+
+// settingsAAD binds encrypted settings to their account
+func settingsAAD(userID string) []byte {
+	return lengthPrefixed(settingsAADDomain, userID)
+}
+
+// loadAccountSettings fetches and decrypts the user's settings. Accounts
+// that have none get the defaults, and on failure the settings are left as
+// they were. Callers must hold the write lock.
+func (app *PastePalApp) loadAccountSettings() error {
+	encrypted, err := app.APIClient.GetAccountSettings()
+	if err != nil {
+		return err
+	}
+	if encrypted == "" {
+		app.settings = DefaultAccountSettings()
+		return nil
+	}
+
+	data, err := crypto.DecryptEnvelope(encrypted, app.keys.SymmetricKey(), settingsAAD(app.CurrentUser.ID))
+	if err != nil {
+		return errors.New("failed to decrypt account settings")
+	}
+	defer clear(data)
+
+	// Settings missing from what was saved keep their defaults
+	settings := DefaultAccountSettings()
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to read account settings: %v", err)
+	}
+	if err := settings.validate(); err != nil {
+		return err
+	}
+
+	app.settings = settings
+	return nil
+}
+
+// GetAccountSettings returns the current user's account settings
+func (app *PastePalApp) GetAccountSettings() (AccountSettings, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn {
+		return AccountSettings{}, errors.New("not logged in")
+	}
+
+	return app.settings, nil
+}
+
+// SaveAccountSettings encrypts the user's account settings and stores them
+// on the server, where the user's other devices pick them up when they next
+// log in or unlock
+func (app *PastePalApp) SaveAccountSettings(settings AccountSettings) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+	if err := settings.validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	// Padded by the new settings, so they hide their own length too
+	previous := app.settings
+	app.settings = settings
+	encrypted, err := crypto.EncryptEnvelope(data, app.keys.SymmetricKey(), settingsAAD(app.CurrentUser.ID), app.pasteEnvelope(data, false))
+	if err == nil {
+		err = app.APIClient.SaveAccountSettings(encrypted)
+	}
+	if err != nil {
+		app.settings = previous
+		return err
+	}
+
+	fmt.Println("[Core] Saved account settings")
+	return nil
+}
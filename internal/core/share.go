@@ -0,0 +1,182 @@
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/auth"
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// loadKeyPair unlocks the user's sharing and signing keys, creating and
+// publishing whatever the account doesn't have yet. Callers must hold the
+// write lock.
+func (app *PastePalApp) loadKeyPair(keyPair *models.KeyPairData) error {
+	if keyPair == nil || keyPair.SigningPublicKey == "" {
+		var err error
+		if keyPair == nil {
+			keyPair, err = auth.CreateKeyPair(app.keys.SymmetricKey())
+		} else {
+			err = auth.AddSigningKey(keyPair, app.keys.SymmetricKey())
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := app.APIClient.PublishKeyPair(keyPair); err != nil {
+			return err
+		}
+	}
+
+	return app.unlockKeyPair(keyPair)
+}
+
+// unlockKeyPair decrypts the private keys of an identity into the key
+// holder. Callers must hold the write lock.
+func (app *PastePalApp) unlockKeyPair(keyPair *models.KeyPairData) error {
+	privateKey, err := auth.UnlockPrivateKey(keyPair, app.keys.SymmetricKey())
+	if err != nil {
+		return err
+	}
+
+	signingKey, err := auth.UnlockSigningKey(keyPair, app.keys.SymmetricKey())
+	if err != nil {
+		clear(privateKey)
+		return err
+	}
+
+	app.keys.SetPrivateKey(privateKey)
+	app.keys.SetSigningKey(signingKey)
+	app.keyPair = keyPair
+	app.CurrentUser.PublicKey = keyPair.PublicKey
+	app.CurrentUser.SigningPublicKey = keyPair.SigningPublicKey
+	return nil
+}
+
+// PublicKeyFingerprint returns the fingerprint of the current user's public
+// key, for comparing with what others see when sharing
+func (app *PastePalApp) PublicKeyFingerprint() (string, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn || app.CurrentUser.PublicKey == "" {
+		return "", errors.New("no sharing key available")
+	}
+
+	return fingerprint(app.CurrentUser.PublicKey)
+}
+
+// LookupRecipient fetches another user's public key and its fingerprint. The
+// fingerprint should be confirmed with the recipient before sharing, since the
+// server could otherwise substitute its own key.
+func (app *PastePalApp) LookupRecipient(email string) (*models.PublicKeyInfo, string, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn {
+		return nil, "", errors.New("not logged in")
+	}
+
+	recipient, err := app.APIClient.LookupPublicKey(strings.TrimSpace(email))
+	if err != nil {
+		return nil, "", err
+	}
+
+	recipientFingerprint, err := fingerprint(recipient.PublicKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return recipient, recipientFingerprint, nil
+}
+
+// SharePaste gives a recipient from LookupRecipient access to a paste by
+// sealing its content key to their public key
+func (app *PastePalApp) SharePaste(pasteID string, recipient *models.PublicKeyInfo) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+
+	recipientKey, err := base64.StdEncoding.DecodeString(recipient.PublicKey)
+	if err != nil {
+		return errors.New("recipient has an invalid public key")
+	}
+
+	paste, err := app.APIClient.GetPaste(pasteID)
+	if err != nil {
+		return err
+	}
+
+	// Older pastes are moved to a key of their own and bound to their ID
+	// before anyone else gets access
+	if paste.EncryptionVersion == models.PasteEncryptionLegacy {
+		paste, err = app.upgradePaste(paste)
+		if err != nil {
+			return err
+		}
+	}
+
+	contentKey, err := app.contentKey(paste)
+	if err != nil {
+		return err
+	}
+
+	sealedKey, err := crypto.SealToPublicKey(contentKey, recipientKey)
+	if err != nil {
+		return err
+	}
+
+	return app.APIClient.SharePaste(pasteID, &models.ShareRequest{
+		RecipientID:  recipient.UserID,
+		EncryptedKey: sealedKey,
+	})
+}
+
+// GetSharedPastes retrieves the pastes other users have shared with the
+// current user
+func (app *PastePalApp) GetSharedPastes() ([]*models.SharedPaste, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn {
+		return nil, errors.New("not logged in")
+	}
+
+	return app.APIClient.GetSharedPastes()
+}
+
+// DecryptSharedPaste decrypts a paste shared with the current user. Its
+// author is checked separately with VerifySharedPaste.
+func (app *PastePalApp) DecryptSharedPaste(shared *models.SharedPaste) (*PasteView, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	if app.keys.PrivateKey() == nil {
+		return nil, errors.New("no sharing key available")
+	}
+
+	contentKey, err := crypto.OpenWithPrivateKey(shared.EncryptedKey, app.keys.PrivateKey())
+	if err != nil {
+		return nil, errors.New("failed to unlock shared paste")
+	}
+
+	return decryptPasteView(&shared.Paste, contentKey)
+}
+
+// fingerprint formats the fingerprint of a base64 public key
+func fingerprint(publicKeyBase64 string) (string, error) {
+	publicKey, err := base64.StdEncoding.DecodeString(publicKeyBase64)
+	if err != nil {
+		return "", errors.New("invalid public key")
+	}
+	return crypto.PublicKeyFingerprint(publicKey), nil
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// SignatureStatus is the outcome of checking a paste's signature
+type SignatureStatus int
+
+const (
+	// SignatureUnverified means the paste is unsigned or its author's key
+	// could not be found
+	SignatureUnverified SignatureStatus = iota
+	// SignatureVerified means the author's key signed the paste as it is
+	SignatureVerified
+	// SignatureInvalid means the signature doesn't match the paste, so the
+	// paste or its metadata was altered
+	SignatureInvalid
+)
+
+func (s SignatureStatus) String() string {
+	switch s {
+	case SignatureVerified:
+		return "verified"
+	case SignatureInvalid:
+		return "invalid"
+	default:
+		return "unverified"
+	}
+}
+
+// PasteAuthor identifies who wrote a paste and whether that is proven
+type PasteAuthor struct {
+	UserID      string
+	Email       string
+	Fingerprint string // Of the signing key, empty when unknown
+	Status      SignatureStatus
+}
+
+// pasteSignatureDomainV2 separates paste signatures from anything else the
+// key might ever sign
+const pasteSignatureDomainV2 = "pastepal-paste-signature-v2"
+
+// pasteSignatureVersion is the format new signatures are made in
+const pasteSignatureVersion = models.PasteSignatureV2
+
+// pasteSignatureMessage builds the bytes a paste signature covers, in the
+// paste's signature format. Every field is length-prefixed so no two pastes
+// produce the same message. It returns nil for formats it doesn't know.
+func pasteSignatureMessage(paste *models.Paste) []byte {
+	var expires int64
+	if !paste.ExpiresAt.IsZero() {
+		expires = paste.ExpiresAt.Unix()
+	}
+
+	fields := []string{
+		paste.UserID,
+		paste.Title,
+		paste.Content,
+		strconv.FormatBool(paste.IsPublic),
+		strconv.FormatInt(expires, 10),
+		strconv.Itoa(paste.MaxAccessCount),
+	}
+
+	if paste.SignatureVersion != models.PasteSignatureV2 {
+		return nil
+	}
+
+	// Without these, a signed title and content could be moved to another
+	// paste or shown with another language and format
+	fields = append(fields, paste.ID, paste.EncryptedContentKey, paste.Language, paste.Format)
+	return lengthPrefixed(append([]string{pasteSignatureDomainV2}, fields...)...)
+}
+
+// signPaste signs the encrypted fields and metadata of one of the current
+// user's pastes in the current signature format, and returns the signature
+// and its format
+func (app *PastePalApp) signPaste(paste *models.Paste) (string, int, error) {
+	if app.keys.SigningKey() == nil {
+		return "", 0, errors.New("no signing key available")
+	}
+
+	signed := *paste
+	signed.UserID = app.CurrentUser.ID
+	signed.SignatureVersion = pasteSignatureVersion
+
+	signature, err := crypto.SignData(pasteSignatureMessage(&signed), app.keys.SigningKey())
+	if err != nil {
+		return "", 0, err
+	}
+	return signature, pasteSignatureVersion, nil
+}
+
+// verifyPaste checks a paste's signature against its author's signing key.
+// signingPublicKey is empty when the author's key is unknown.
+func verifyPaste(paste *models.Paste, email, signingPublicKey string) *PasteAuthor {
+	author := &PasteAuthor{
+		UserID: paste.UserID,
+		Email:  email,
+		Status: SignatureUnverified,
+	}
+
+	if signingPublicKey == "" {
+		return author
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(signingPublicKey)
+	if err != nil {
+		return author
+	}
+	author.Fingerprint = crypto.PublicKeyFingerprint(publicKey)
+
+	if paste.Signature == "" {
+		return author
+	}
+
+	// A signature in any other format could be one that covers less of the
+	// paste, so it counts as not matching
+	message := pasteSignatureMessage(paste)
+	if message != nil && crypto.VerifySignature(message, paste.Signature, publicKey) {
+		author.Status = SignatureVerified
+	} else {
+		author.Status = SignatureInvalid
+	}
+	return author
+}
+
+// SigningKeyFingerprint returns the fingerprint of the current user's signing
+// key, which others see as the author of signed pastes
+func (app *PastePalApp) SigningKeyFingerprint() (string, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn || app.CurrentUser.SigningPublicKey == "" {
+		return "", errors.New("no signing key available")
+	}
+
+	return fingerprint(app.CurrentUser.SigningPublicKey)
+}
+
+// VerifySharedPaste checks who wrote a paste shared with the current user,
+// using the signing key the owner has published
+func (app *PastePalApp) VerifySharedPaste(shared *models.SharedPaste) (*PasteAuthor, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn {
+		return nil, errors.New("not logged in")
+	}
+
+	owner, err := app.APIClient.LookupPublicKey(shared.OwnerEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	// The published key only speaks for the paste if it belongs to its owner
+	if owner.UserID != shared.Paste.UserID || owner.UserID != shared.OwnerID {
+		author := verifyPaste(&shared.Paste, shared.OwnerEmail, "")
+		if shared.Paste.Signature != "" {
+			author.Status = SignatureInvalid
+		}
+		return author, nil
+	}
+
+	return verifyPaste(&shared.Paste, shared.OwnerEmail, owner.SigningPublicKey), nil
+}
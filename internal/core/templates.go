@@ -0,0 +1,243 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/crypto"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// templateAADDomain separates template associated data from any other use
+// of the symmetric key
+const templateAADDomain = "pastepal-template-aad-v1"
+
+// Placeholders filled in without asking: the date and time the paste is
+// created
+const (
+	TemplateDate = "date"
+	TemplateTime = "time"
+)
+
+// placeholderPattern matches a placeholder such as {{ service }}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// Template is one of the user's paste templates with its data decrypted.
+// Its title and content may hold {{name}} placeholders.
+type Template struct {
+	ID       string   `json:"-"` // Empty until the template is saved
+	Name     string   `json:"name"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	Language string   `json:"language,omitempty"`
+	Format   string   `json:"format,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	IsPublic bool     `json:"is_public"`
+	// How long pastes made from the template last, 0 for no expiry
+	ExpiryHours int `json:"expiry_hours,omitempty"`
+}
+
+// Placeholders returns the names of the placeholders that have to be filled
+// in, in the order they first appear. The date and time aren't included.
+func (t *Template) Placeholders() []string {
+	seen := map[string]bool{TemplateDate: true, TemplateTime: true}
+	var names []string
+	for _, text := range []string{t.Title, t.Content} {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// Fill replaces the placeholders in the title and content with values. The
+// date and time are filled in unless values has them.
+func (t *Template) Fill(values map[string]string) (string, string, error) {
+	now := time.Now()
+	all := map[string]string{
+		TemplateDate: now.Format("2006-01-02"),
+		TemplateTime: now.Format("15:04"),
+	}
+	for name, value := range values {
+		all[name] = value
+	}
+
+	var missing []string
+	reported := make(map[string]bool)
+	fill := func(text string) string {
+		return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+			value, ok := all[name]
+			if !ok {
+				if !reported[name] {
+					reported[name] = true
+					missing = append(missing, name)
+				}
+				return placeholder
+			}
+			return value
+		})
+	}
+
+	title, content := fill(t.Title), fill(t.Content)
+	if len(missing) > 0 {
+		return "", "", fmt.Errorf("no value for %s", strings.Join(missing, ", "))
+	}
+	return title, content, nil
+}
+
+// templateAAD binds an encrypted template to its owner and ID
+func templateAAD(ownerID, templateID string) []byte {
+	return lengthPrefixed(templateAADDomain, ownerID, templateID)
+}
+
+// encryptTemplate encrypts a template bound to its ID
+func (app *PastePalApp) encryptTemplate(templateID string, template *Template) (string, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	defer clear(data)
+
+	aad := templateAAD(app.CurrentUser.ID, templateID)
+	return crypto.EncryptEnvelope(data, app.keys.SymmetricKey(), aad, app.pasteEnvelope(data, false))
+}
+
+// decryptTemplate decrypts one of the user's templates
+func (app *PastePalApp) decryptTemplate(encrypted *models.Template) (*Template, error) {
+	data, err := crypto.DecryptEnvelope(encrypted.Data, app.keys.SymmetricKey(), templateAAD(encrypted.UserID, encrypted.ID))
+	if err != nil {
+		return nil, errors.New("failed to decrypt template")
+	}
+	defer clear(data)
+
+	template := &Template{}
+	if err := json.Unmarshal(data, template); err != nil {
+		return nil, fmt.Errorf("failed to read template: %v", err)
+	}
+	template.ID = encrypted.ID
+	return template, nil
+}
+
+// GetTemplates retrieves and decrypts the user's templates, sorted by name
+func (app *PastePalApp) GetTemplates() ([]*Template, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := app.APIClient.GetTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]*Template, 0, len(encrypted))
+	for _, template := range encrypted {
+		decrypted, err := app.decryptTemplate(template)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, decrypted)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return strings.ToLower(templates[i].Name) < strings.ToLower(templates[j].Name)
+	})
+	return templates, nil
+}
+
+// SaveTemplate encrypts a template and stores it on the server, creating it
+// when it has no ID yet. The template's ID is set once it is created.
+func (app *PastePalApp) SaveTemplate(template *Template) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+
+	name, err := cleanName("template", template.Name)
+	if err != nil {
+		return err
+	}
+	template.Name = name
+
+	if template.Tags, err = normalizeTags(template.Tags); err != nil {
+		return err
+	}
+	if template.ExpiryHours < 0 {
+		return errors.New("template expiry can't be negative")
+	}
+	switch template.Format {
+	case "", models.PasteFormatPlain, models.PasteFormatCode, models.PasteFormatMarkdown:
+	default:
+		return fmt.Errorf("unknown paste format %q", template.Format)
+	}
+
+	if template.ID != "" {
+		return app.updateTemplate(template.ID, template)
+	}
+
+	// The data is bound to the template ID, so it is chosen up front like a
+	// paste ID
+	templateID, err := newPasteID()
+	if err != nil {
+		return err
+	}
+
+	data, err := app.encryptTemplate(templateID, template)
+	if err != nil {
+		return err
+	}
+
+	created, err := app.APIClient.CreateTemplate(&models.CreateTemplateRequest{ID: templateID, Data: data})
+	if err != nil {
+		return err
+	}
+
+	// Servers that assign their own IDs need the data bound to that one
+	if created.ID != templateID {
+		if err := app.updateTemplate(created.ID, template); err != nil {
+			return err
+		}
+	}
+
+	template.ID = created.ID
+	fmt.Printf("[Core] Created template %s\n", created.ID)
+	return nil
+}
+
+// updateTemplate re-encrypts a template for the given ID and replaces the
+// stored one. Callers must hold the lock.
+func (app *PastePalApp) updateTemplate(templateID string, template *Template) error {
+	data, err := app.encryptTemplate(templateID, template)
+	if err != nil {
+		return err
+	}
+
+	_, err = app.APIClient.UpdateTemplate(templateID, &models.UpdateTemplateRequest{Data: data})
+	return err
+}
+
+// DeleteTemplate deletes one of the user's templates. Pastes made from it
+// are kept.
+func (app *PastePalApp) DeleteTemplate(templateID string) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn {
+		return errors.New("not logged in")
+	}
+
+	return app.APIClient.DeleteTemplate(templateID)
+}
@@ -0,0 +1,329 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// trashPurgeInterval is how often the trash is purged while the vault is
+// unlocked, so a session left open for days still purges on time
+const trashPurgeInterval = time.Hour
+
+// TrashedPaste is a paste in the trash with its title decrypted
+type TrashedPaste struct {
+	Paste *models.Paste
+	Title string
+	// When the paste is purged for good, zero when it isn't known when it
+	// was deleted
+	PurgeAt time.Time
+}
+
+// TrashPaste moves one of the user's pastes to the trash, where it can be
+// restored until the account's trash retention period is over
+func (app *PastePalApp) TrashPaste(pasteID string) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+
+	if err := app.APIClient.TrashPaste(pasteID); err != nil {
+		return err
+	}
+
+	// Restoring the paste indexes it again at the next sync
+	app.unindexPaste(pasteID)
+
+	fmt.Printf("[Core] Moved paste %s to the trash\n", pasteID)
+	return nil
+}
+
+// RestorePaste moves a paste out of the trash
+func (app *PastePalApp) RestorePaste(pasteID string) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if !app.IsLoggedIn {
+		return errors.New("not logged in")
+	}
+
+	if err := app.APIClient.RestorePaste(pasteID); err != nil {
+		return err
+	}
+
+	fmt.Printf("[Core] Restored paste %s from the trash\n", pasteID)
+	return nil
+}
+
+// GetTrash returns the pastes in the trash, most recently deleted first.
+// Pastes past the retention period are purged rather than returned.
+func (app *PastePalApp) GetTrash() ([]*TrashedPaste, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return nil, err
+	}
+
+	pastes, _, err := app.purgeExpiredTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	retention := app.trashRetention()
+	trashed := make([]*TrashedPaste, 0, len(pastes))
+	for _, paste := range pastes {
+		title := "(unable to decrypt)"
+		if contentKey, err := app.contentKey(paste); err == nil {
+			if decrypted, _, err := decryptPaste(paste, contentKey); err == nil {
+				title = decrypted
+			}
+		}
+
+		entry := &TrashedPaste{Paste: paste, Title: title}
+		if !paste.DeletedAt.IsZero() {
+			entry.PurgeAt = paste.DeletedAt.Add(retention)
+		}
+		trashed = append(trashed, entry)
+	}
+
+	sort.SliceStable(trashed, func(i, j int) bool {
+		return trashed[i].Paste.DeletedAt.After(trashed[j].Paste.DeletedAt)
+	})
+	return trashed, nil
+}
+
+// DeletePastePermanently deletes a paste in the trash from the server and
+// from this device's cache. It can't be undone.
+func (app *PastePalApp) DeletePastePermanently(pasteID string) error {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return err
+	}
+
+	return app.purgePaste(pasteID)
+}
+
+// EmptyTrash permanently deletes every paste in the trash, returning how
+// many were deleted before any error
+func (app *PastePalApp) EmptyTrash() (int, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	if err := app.requireKeys(); err != nil {
+		return 0, err
+	}
+
+	pastes, err := app.APIClient.GetTrash()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, paste := range pastes {
+		if err := app.purgePaste(paste.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(pastes), nil
+}
+
+// PurgeTrash permanently deletes the pastes that have been in the trash
+// longer than the retention period, and drops cached copies of pastes that
+// are gone from the server. It returns how many pastes were purged.
+func (app *PastePalApp) PurgeTrash() (int, error) {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	// Purging runs in the background, so it doesn't count as activity
+	if !app.IsLoggedIn {
+		return 0, errors.New("not logged in")
+	}
+	if app.locked {
+		return 0, ErrLocked
+	}
+
+	remaining, purged, err := app.purgeExpiredTrash()
+	if err != nil {
+		return purged, err
+	}
+
+	pastes, err := app.APIClient.GetUserPastes()
+	if err != nil {
+		return purged, err
+	}
+
+	// Only pastes outside the trash are searchable
+	known := make(map[string]bool, len(pastes)+len(remaining))
+	for _, paste := range pastes {
+		known[paste.ID] = true
+	}
+	app.pruneSearchIndex(known)
+
+	// Pastes purged on another device, or by the server itself, still have
+	// a copy here
+	for _, paste := range remaining {
+		known[paste.ID] = true
+	}
+	if err := app.pruneLocalPastes(known); err != nil {
+		return purged, err
+	}
+
+	return purged, nil
+}
+
+// trashRetention returns how long pastes stay in the trash. It is an
+// account setting, so all of the user's devices purge at the same time.
+// Callers must hold the lock.
+func (app *PastePalApp) trashRetention() time.Duration {
+	return time.Duration(app.settings.TrashRetentionDays) * 24 * time.Hour
+}
+
+// purgeExpiredTrash purges the pastes past the retention period. It returns
+// the rest of the trash and how many pastes were purged. Callers must hold
+// the lock.
+func (app *PastePalApp) purgeExpiredTrash() ([]*models.Paste, int, error) {
+	pastes, err := app.APIClient.GetTrash()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Pastes the server didn't record a deletion time for count as deleted
+	// when this device first saw them in the trash. They are kept rather
+	// than purged, since they may have been deleted a moment ago.
+	var undated []string
+	for _, paste := range pastes {
+		if paste.DeletedAt.IsZero() {
+			undated = append(undated, paste.ID)
+		}
+	}
+	seen, err := app.LocalStorage.NoteTrashed(app.CurrentUser.ID, undated)
+	if err != nil {
+		fmt.Printf("[Core] Failed to record when pastes were trashed: %v\n", err)
+	}
+	for _, paste := range pastes {
+		if paste.DeletedAt.IsZero() {
+			paste.DeletedAt = seen[paste.ID]
+		}
+	}
+
+	cutoff := time.Now().Add(-app.trashRetention())
+	remaining := make([]*models.Paste, 0, len(pastes))
+	purged := 0
+	for _, paste := range pastes {
+		if paste.DeletedAt.IsZero() || paste.DeletedAt.After(cutoff) {
+			remaining = append(remaining, paste)
+			continue
+		}
+
+		if err := app.purgePaste(paste.ID); err != nil {
+			return nil, purged, fmt.Errorf("failed to purge paste %s: %v", paste.ID, err)
+		}
+		purged++
+	}
+
+	return remaining, purged, nil
+}
+
+// purgePaste deletes a paste from the server, the local cache and the
+// search index. Callers must hold the lock with the keys available.
+func (app *PastePalApp) purgePaste(pasteID string) error {
+	if err := app.APIClient.PurgePaste(pasteID); err != nil {
+		return err
+	}
+	app.unindexPaste(pasteID)
+
+	if err := app.LocalStorage.DeletePasteLocally(pasteID); err != nil {
+		return fmt.Errorf("paste %s was deleted from the server but not from this device: %v", pasteID, err)
+	}
+
+	fmt.Printf("[Core] Permanently deleted paste %s\n", pasteID)
+	return nil
+}
+
+// pruneLocalPastes deletes the user's cached pastes that aren't in known.
+// Callers must hold the lock.
+func (app *PastePalApp) pruneLocalPastes(known map[string]bool) error {
+	cached, err := app.LocalStorage.GetLocalPastes()
+	if err != nil {
+		return err
+	}
+
+	for _, paste := range cached {
+		if paste.UserID != app.CurrentUser.ID || known[paste.ID] {
+			continue
+		}
+
+		if err := app.LocalStorage.DeletePasteLocally(paste.ID); err != nil {
+			return err
+		}
+		fmt.Printf("[Core] Dropped cached copy of deleted paste %s\n", paste.ID)
+	}
+
+	return nil
+}
+
+// StartTrashPurge purges the trash now and then every trashPurgeInterval
+// while a user is logged in with the vault unlocked. Locking or logging out
+// pauses it until the vault is unlocked or a user logs in again.
+func (app *PastePalApp) StartTrashPurge() {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	app.purgeTrash = true
+	if app.IsLoggedIn && !app.locked {
+		app.startTrashPurge()
+	}
+}
+
+// startTrashPurge starts purging the trash in the background if
+// StartTrashPurge asked for it. Callers must hold the write lock.
+func (app *PastePalApp) startTrashPurge() {
+	if !app.purgeTrash || app.stopTrashPurge != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go app.runTrashPurge(done)
+	app.stopTrashPurge = func() { close(done) }
+}
+
+// haltTrashPurge stops purging the trash in the background. Callers must
+// hold the write lock.
+func (app *PastePalApp) haltTrashPurge() {
+	if app.stopTrashPurge != nil {
+		app.stopTrashPurge()
+		app.stopTrashPurge = nil
+	}
+}
+
+// runTrashPurge purges the trash until done is closed
+func (app *PastePalApp) runTrashPurge(done <-chan struct{}) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		if _, err := app.PurgeTrash(); err != nil {
+			fmt.Printf("[Core] Failed to purge the trash: %v\n", err)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JacobRWebb/PastePal-OS/internal/auth"
+	"github.com/JacobRWebb/PastePal-OS/internal/models"
+)
+
+// pendingLogin holds a password login that is waiting for its second
+// factor. The password itself is not kept: its master key is derived before
+// the prompt and held in keys, and so is the SRP verifier an upgrade needs.
+type pendingLogin struct {
+	email          string
+	keys           KeyHolder
+	rememberMe     bool
+	srpClient      *auth.SRPClient // nil for password hash logins
+	srpVerifier    *models.SRPVerifierData
+	challengeToken string
+}
+
+// dropPendingLogin abandons a login waiting for its second factor and wipes
+// its master key. Callers must hold the write lock.
+func (app *PastePalApp) dropPendingLogin() {
+	if app.pendingLogin != nil {
+		app.pendingLogin.keys.Wipe()
+		app.pendingLogin = nil
+	}
+}
+
+// HasPendingTwoFactorLogin reports whether Login is waiting for a 2FA code
+func (app *PastePalApp) HasPendingTwoFactorLogin() bool {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	return app.pendingLogin != nil
+}
+
+// CompleteTwoFactorLogin finishes a login that returned api.ErrTwoFactorRequired,
+// using either a TOTP code or a one-time recovery code
+func (app *PastePalApp) CompleteTwoFactorLogin(code string, isRecoveryCode bool) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	pending := app.pendingLogin
+	if pending == nil {
+		return errors.New("no login is waiting for a two-factor code")
+	}
+
+	twoFactorReq := &models.TwoFactorLoginRequest{ChallengeToken: pending.challengeToken}
+	if isRecoveryCode {
+		twoFactorReq.RecoveryCode = strings.TrimSpace(code)
+	} else {
+		twoFactorReq.Code = strings.TrimSpace(code)
+	}
+
+	loginResp, err := app.APIClient.LoginTwoFactor(twoFactorReq, pending.srpClient)
+	if errors.Is(err, auth.ErrServerProof) {
+		// The server couldn't prove it knows the verifier, so don't retry
+		app.dropPendingLogin()
+		return err
+	}
+	if err != nil {
+		// Keep the pending login so a mistyped code can be retried
+		return err
+	}
+
+	defer app.dropPendingLogin()
+	return app.finishLogin(pending.email, pending.keys.MasterKey(), pending.rememberMe, pending.srpClient != nil, pending.srpVerifier, loginResp)
+}
+
+// CancelTwoFactorLogin abandons a login waiting for a second factor
+func (app *PastePalApp) CancelTwoFactorLogin() {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	app.dropPendingLogin()
+}
+
+// BeginTwoFactorSetup requests a new TOTP secret for the current user
+func (app *PastePalApp) BeginTwoFactorSetup() (*models.TwoFactorSetupResponse, error) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if !app.IsLoggedIn {
+		return nil, errors.New("not logged in")
+	}
+
+	setup, err := app.APIClient.BeginTwoFactorSetup()
+	if err != nil {
+		return nil, err
+	}
+
+	app.pendingSecret = setup.Secret
+	return setup, nil
+}
+
+// EnableTwoFactor confirms the secret from BeginTwoFactorSetup and returns the
+// recovery codes, which must be shown to the user exactly once
+func (app *PastePalApp) EnableTwoFactor(code string) ([]string, error) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if !app.IsLoggedIn {
+		return nil, errors.New("not logged in")
+	}
+
+	if app.pendingSecret == "" {
+		return nil, errors.New("two-factor setup has not been started")
+	}
+
+	// Catch typos and clock drift before the server locks the secret in
+	code = strings.TrimSpace(code)
+	if !auth.ValidateTOTPCode(app.pendingSecret, code, time.Now()) {
+		return nil, errors.New("code does not match; check the code and your device clock")
+	}
+
+	recoveryCodes, err := app.APIClient.EnableTwoFactor(code)
+	if err != nil {
+		return nil, err
+	}
+
+	app.pendingSecret = ""
+	app.CurrentUser.TwoFactorEnabled = true
+
+	return recoveryCodes, nil
+}
+
+// DisableTwoFactor turns off 2FA, confirmed by a TOTP or recovery code
+func (app *PastePalApp) DisableTwoFactor(code string, isRecoveryCode bool) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if !app.IsLoggedIn {
+		return errors.New("not logged in")
+	}
+
+	confirm := &models.TwoFactorCodeRequest{}
+	if isRecoveryCode {
+		confirm.RecoveryCode = strings.TrimSpace(code)
+	} else {
+		confirm.Code = strings.TrimSpace(code)
+	}
+
+	if err := app.APIClient.DisableTwoFactor(confirm); err != nil {
+		return err
+	}
+
+	app.CurrentUser.TwoFactorEnabled = false
+	return nil
+}
@@ -0,0 +1,129 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters for new archive keys
+const (
+	ArchiveArgonTime    uint32 = 3
+	ArchiveArgonMemory  uint32 = 64 * 1024 // KiB
+	ArchiveArgonThreads uint8  = 4
+)
+
+// Highest costs accepted when opening an archive, so a crafted header can't
+// make the derivation run for hours or exhaust memory
+const (
+	maxArchiveArgonTime   uint32 = 16
+	maxArchiveArgonMemory uint32 = 1024 * 1024 // KiB
+)
+
+const (
+	archiveFormat  = "pastepal-archive"
+	archiveVersion = 1
+	archiveKDF     = "argon2id"
+	// Longest header line read before giving up on a file
+	maxArchiveHeaderSize = 4096
+)
+
+var (
+	// ErrNotArchive is returned for files that aren't PastePal archives
+	ErrNotArchive = errors.New("not a PastePal archive")
+	// ErrArchivePassphrase is returned when an archive fails to decrypt
+	ErrArchivePassphrase = errors.New("wrong passphrase, or the archive is damaged")
+)
+
+// archiveHeader is the unencrypted first line of an archive file. It holds
+// what is needed to derive the key from the passphrase, and is bound to the
+// ciphertext as associated data so it can't be altered.
+type archiveHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Salt    []byte `json:"salt"`
+}
+
+// SealArchive encrypts data with a key derived from passphrase and writes it
+// as an archive file: a JSON header line followed by the AES-GCM ciphertext
+func SealArchive(w io.Writer, data []byte, passphrase string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	header := archiveHeader{
+		Format:  archiveFormat,
+		Version: archiveVersion,
+		KDF:     archiveKDF,
+		Time:    ArchiveArgonTime,
+		Memory:  ArchiveArgonMemory,
+		Threads: ArchiveArgonThreads,
+		Salt:    salt,
+	}
+	headerLine, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	key := argon2.IDKey([]byte(passphrase), salt, header.Time, header.Memory, header.Threads, 32)
+	ciphertext, err := encryptWithAAD(data, key, headerLine)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(append(headerLine, '\n')); err != nil {
+		return err
+	}
+	_, err = w.Write(ciphertext)
+	return err
+}
+
+// OpenArchive reads an archive file written by SealArchive and decrypts it
+// with passphrase
+func OpenArchive(r io.Reader, passphrase string) ([]byte, error) {
+	reader := bufio.NewReaderSize(r, maxArchiveHeaderSize)
+	headerLine, err := reader.ReadSlice('\n')
+	if err != nil {
+		return nil, ErrNotArchive
+	}
+	headerLine = bytes.TrimSuffix(headerLine, []byte("\n"))
+
+	var header archiveHeader
+	if err := json.Unmarshal(headerLine, &header); err != nil || header.Format != archiveFormat {
+		return nil, ErrNotArchive
+	}
+	if header.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", header.Version)
+	}
+	if header.KDF != archiveKDF || len(header.Salt) < 16 || header.Time == 0 || header.Threads == 0 ||
+		header.Time > maxArchiveArgonTime || header.Memory > maxArchiveArgonMemory {
+		return nil, errors.New("unsupported archive key derivation settings")
+	}
+
+	// The header line is still in the reader's buffer, so copy it before
+	// reading on
+	aad := append([]byte(nil), headerLine...)
+
+	ciphertext, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	key := argon2.IDKey([]byte(passphrase), header.Salt, header.Time, header.Memory, header.Threads, 32)
+	data, err := decryptWithAAD(ciphertext, key, aad)
+	if err != nil {
+		return nil, ErrArchivePassphrase
+	}
+	return data, nil
+}
@@ -0,0 +1,245 @@
+package crypto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies how the payload inside an envelope is compressed
+type Compression byte
+
+const (
+	CompressionNone Compression = 0
+	CompressionGzip Compression = 1
+	CompressionZstd Compression = 2
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(c))
+	}
+}
+
+// EnvelopeOptions controls how data is prepared before it is encrypted
+type EnvelopeOptions struct {
+	Compression Compression
+	Padding     Padding
+}
+
+// An envelope is the plaintext that gets encrypted. It is a version byte, a
+// compression byte, a padding byte and the payload length as a big-endian
+// uint32, followed by the payload and zero padding. Version 1 envelopes had
+// no padding and are no longer accepted.
+const (
+	envelopeVersion    = 2
+	envelopeHeaderSize = 1 + 1 + 1 + 4
+)
+
+const (
+	// Below this size compression saves too little to be worth it
+	minCompressSize = 512
+	// From this size on zstd beats gzip clearly enough to be used
+	zstdMinSize = 4 * 1024
+	// Largest payload an envelope may decompress to
+	maxEnvelopeSize = 64 * 1024 * 1024
+)
+
+// incompressibleTypes are content types that are already compressed
+var incompressibleTypes = []string{
+	"image/",
+	"audio/",
+	"video/",
+	"font/woff",
+	"application/zip",
+	"application/x-gzip",
+	"application/x-rar-compressed",
+	"application/pdf",
+	"application/wasm",
+}
+
+// ChooseCompression picks a compression for data by its size and content
+// type. Callers must pass CompressionNone instead for data that mixes secrets
+// with attacker-controlled input, since the compressed size can reveal the
+// secrets.
+func ChooseCompression(data []byte) Compression {
+	if len(data) < minCompressSize {
+		return CompressionNone
+	}
+
+	contentType := http.DetectContentType(data)
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return CompressionNone
+		}
+	}
+
+	// zstd frames only pay off once there is enough data
+	if len(data) < zstdMinSize {
+		return CompressionGzip
+	}
+	return CompressionZstd
+}
+
+// SealEnvelope wraps data in an envelope, compressing it when that makes it
+// smaller and padding it as requested
+func SealEnvelope(data []byte, opts EnvelopeOptions) ([]byte, error) {
+	payload := data
+	compression := opts.Compression
+	if compression != CompressionNone {
+		compressed, err := compress(data, compression)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(compressed) < len(data) {
+			payload = compressed
+		} else {
+			compression = CompressionNone
+		}
+	}
+
+	if uint64(len(payload)) > math.MaxUint32 {
+		return nil, errors.New("envelope payload too large")
+	}
+
+	// Padding covers the header too, so the whole plaintext length is a bucket
+	size := PaddedLength(envelopeHeaderSize+len(payload), opts.Padding)
+	envelope := make([]byte, envelopeHeaderSize, size)
+	envelope[0] = envelopeVersion
+	envelope[1] = byte(compression)
+	envelope[2] = byte(opts.Padding)
+	binary.BigEndian.PutUint32(envelope[3:], uint32(len(payload)))
+	envelope = append(envelope, payload...)
+	return envelope[:size], nil
+}
+
+// OpenEnvelope returns the data inside an envelope
+func OpenEnvelope(envelope []byte) ([]byte, error) {
+	if len(envelope) == 0 {
+		return nil, errors.New("malformed envelope")
+	}
+	if envelope[0] != envelopeVersion {
+		return nil, fmt.Errorf("unsupported envelope version %d", envelope[0])
+	}
+	if len(envelope) < envelopeHeaderSize {
+		return nil, errors.New("malformed envelope")
+	}
+
+	compression := Compression(envelope[1])
+	length := binary.BigEndian.Uint32(envelope[3:envelopeHeaderSize])
+	if uint64(length) > uint64(len(envelope)-envelopeHeaderSize) {
+		return nil, errors.New("malformed envelope: length exceeds data")
+	}
+	payload := envelope[envelopeHeaderSize : envelopeHeaderSize+int(length)]
+
+	if compression == CompressionNone {
+		return payload, nil
+	}
+	return decompress(payload, compression)
+}
+
+// EncryptEnvelope seals data in an envelope and encrypts it bound to aad
+func EncryptEnvelope(data, symmetricKey, aad []byte, opts EnvelopeOptions) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("no data to encrypt")
+	}
+
+	envelope, err := SealEnvelope(data, opts)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := encryptWithAAD(envelope, symmetricKey, aad)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// DecryptEnvelope decrypts data encrypted with EncryptEnvelope
+func DecryptEnvelope(encryptedDataBase64 string, symmetricKey, aad []byte) ([]byte, error) {
+	envelope, err := DecryptDataWithAAD(encryptedDataBase64, symmetricKey, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	return OpenEnvelope(envelope)
+}
+
+// compress compresses data with the given algorithm
+func compress(data []byte, compression Compression) ([]byte, error) {
+	switch compression {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		writer, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+		if err != nil {
+			return nil, err
+		}
+		defer encoder.Close()
+		return encoder.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", compression)
+	}
+}
+
+// decompress reverses compress, refusing output over maxEnvelopeSize
+func decompress(payload []byte, compression Compression) ([]byte, error) {
+	var reader io.Reader
+	switch compression {
+	case CompressionGzip:
+		gzipReader, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(bytes.NewReader(payload), zstd.WithDecoderMaxMemory(maxEnvelopeSize))
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		reader = decoder
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", compression)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxEnvelopeSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxEnvelopeSize {
+		return nil, errors.New("envelope payload too large")
+	}
+	return data, nil
+}
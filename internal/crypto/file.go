@@ -30,6 +30,31 @@ func DecryptData(encryptedDataBase64 string, symmetricKey []byte) ([]byte, error
 	return decrypt(encryptedData, symmetricKey)
 }
 
+// EncryptDataWithAAD encrypts data using the provided symmetric key and binds
+// it to aad, which must be given again to decrypt
+func EncryptDataWithAAD(data, symmetricKey, aad []byte) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("no data to encrypt")
+	}
+
+	encrypted, err := encryptWithAAD(data, symmetricKey, aad)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// DecryptDataWithAAD decrypts data encrypted with EncryptDataWithAAD
+func DecryptDataWithAAD(encryptedDataBase64 string, symmetricKey, aad []byte) ([]byte, error) {
+	encryptedData, err := base64.StdEncoding.DecodeString(encryptedDataBase64)
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptWithAAD(encryptedData, symmetricKey, aad)
+}
+
 // EncryptReader encrypts data from a reader using the provided symmetric key
 func EncryptReader(reader io.Reader, symmetricKey []byte) (string, error) {
 	data, err := io.ReadAll(reader)
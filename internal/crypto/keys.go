@@ -54,6 +54,12 @@ func DecryptSymmetricKey(encryptedKeyBase64 string, masterKey []byte) ([]byte, e
 
 // encrypt performs AES-GCM encryption
 func encrypt(data, key []byte) ([]byte, error) {
+	return encryptWithAAD(data, key, nil)
+}
+
+// encryptWithAAD performs AES-GCM encryption that authenticates aad along
+// with the data
+func encryptWithAAD(data, key, aad []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -69,12 +75,18 @@ func encrypt(data, key []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
+	ciphertext := gcm.Seal(nonce, nonce, data, aad)
 	return ciphertext, nil
 }
 
 // decrypt performs AES-GCM decryption
 func decrypt(data, key []byte) ([]byte, error) {
+	return decryptWithAAD(data, key, nil)
+}
+
+// decryptWithAAD performs AES-GCM decryption, failing unless aad matches
+// what the data was encrypted with
+func decryptWithAAD(data, key, aad []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -90,7 +102,7 @@ func decrypt(data, key []byte) ([]byte, error) {
 	}
 
 	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
-	return gcm.Open(nil, nonce, ciphertext, nil)
+	return gcm.Open(nil, nonce, ciphertext, aad)
 }
 
 // normalizeEmail standardizes an email for use as a salt
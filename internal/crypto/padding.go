@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Padding identifies how an envelope is padded to hide its exact length
+type Padding byte
+
+const (
+	PaddingNone Padding = 0
+	// PaddingPadme rounds up to a PADMÉ length, which costs at most about
+	// 12% and leaks O(log log n) bits of the length
+	PaddingPadme Padding = 1
+	// PaddingPowerOfTwo rounds up to the next power of two, which costs up
+	// to 100% and leaks O(log log n) bits as well, in fewer buckets
+	PaddingPowerOfTwo Padding = 2
+)
+
+// minPaddedSize is the smallest padded length, so that short secrets such as
+// passwords can't be told apart by length
+const minPaddedSize = 64
+
+func (p Padding) String() string {
+	switch p {
+	case PaddingNone:
+		return "none"
+	case PaddingPadme:
+		return "padme"
+	case PaddingPowerOfTwo:
+		return "power_of_two"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(p))
+	}
+}
+
+// PaddedLength returns the length n bytes are padded to
+func PaddedLength(n int, padding Padding) int {
+	if padding == PaddingNone {
+		return n
+	}
+
+	if n < minPaddedSize {
+		return minPaddedSize
+	}
+
+	switch padding {
+	case PaddingPowerOfTwo:
+		return 1 << bits.Len(uint(n-1))
+	case PaddingPadme:
+		return padme(n)
+	default:
+		return n
+	}
+}
+
+// padme implements the PADMÉ length function from "Reducing Metadata Leakage
+// from Encrypted Files and Communication with PURBs": the lowest bits of the
+// length are rounded up so that only O(log log n) bits stay significant
+func padme(n int) int {
+	exponent := bits.Len(uint(n)) - 1
+	significant := bits.Len(uint(exponent))
+	roundedBits := exponent - significant
+	mask := (1 << roundedBits) - 1
+	return (n + mask) &^ mask
+}
@@ -0,0 +1,18 @@
+package crypto
+
+import (
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters for new PIN keys. PINs have little entropy, so the
+// derivation is made deliberately slow and memory hard.
+const (
+	PINArgonTime    uint32 = 3
+	PINArgonMemory  uint32 = 64 * 1024 // KiB
+	PINArgonThreads uint8  = 4
+)
+
+// DeriveKeyFromPIN derives a 256-bit key from a PIN with Argon2id
+func DeriveKeyFromPIN(pin string, salt []byte, time, memory uint32, threads uint8) []byte {
+	return argon2.IDKey([]byte(pin), salt, time, memory, threads, 32)
+}
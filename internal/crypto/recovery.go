@@ -0,0 +1,108 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// RecoveryKeySize is the number of random bytes in a recovery key (128 bits)
+const RecoveryKeySize = 16
+
+// GenerateRecoveryKey creates a new random recovery key
+func GenerateRecoveryKey() ([]byte, error) {
+	key := make([]byte, RecoveryKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// EncodeRecoveryMnemonic turns a recovery key into a printable list of words.
+// A final checksum word catches most transcription mistakes.
+func EncodeRecoveryMnemonic(recoveryKey []byte) string {
+	words := make([]string, 0, len(recoveryKey)+1)
+	for _, b := range recoveryKey {
+		words = append(words, recoveryWords[b])
+	}
+	words = append(words, recoveryWords[recoveryChecksum(recoveryKey)])
+
+	return strings.Join(words, " ")
+}
+
+// DecodeRecoveryMnemonic parses a mnemonic produced by EncodeRecoveryMnemonic
+func DecodeRecoveryMnemonic(mnemonic string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) != RecoveryKeySize+1 {
+		return nil, fmt.Errorf("recovery key must have %d words, got %d", RecoveryKeySize+1, len(words))
+	}
+
+	decoded := make([]byte, len(words))
+	for i, word := range words {
+		index, ok := recoveryWordIndex(word)
+		if !ok {
+			return nil, fmt.Errorf("unknown word %q in recovery key", word)
+		}
+		decoded[i] = index
+	}
+
+	recoveryKey := decoded[:RecoveryKeySize]
+	if decoded[RecoveryKeySize] != recoveryChecksum(recoveryKey) {
+		return nil, errors.New("recovery key checksum does not match; check the words for typos")
+	}
+
+	return recoveryKey, nil
+}
+
+// DeriveRecoveryWrappingKey derives the key that wraps the symmetric key for
+// account recovery
+func DeriveRecoveryWrappingKey(recoveryKey []byte) ([]byte, error) {
+	return deriveRecoverySubkey(recoveryKey, "pastepal-recovery-wrap")
+}
+
+// DeriveRecoveryAuthHash derives the value the server checks to authorise a
+// recovery. It is independent of the wrapping key, so the server can't use it
+// to unwrap anything.
+func DeriveRecoveryAuthHash(recoveryKey []byte) (string, error) {
+	authKey, err := deriveRecoverySubkey(recoveryKey, "pastepal-recovery-auth")
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(authKey), nil
+}
+
+// deriveRecoverySubkey expands the recovery key into a purpose-bound subkey
+func deriveRecoverySubkey(recoveryKey []byte, purpose string) ([]byte, error) {
+	if len(recoveryKey) != RecoveryKeySize {
+		return nil, errors.New("invalid recovery key length")
+	}
+
+	subkey := make([]byte, 32)
+	reader := hkdf.New(sha256.New, recoveryKey, nil, []byte(purpose))
+	if _, err := io.ReadFull(reader, subkey); err != nil {
+		return nil, err
+	}
+	return subkey, nil
+}
+
+// recoveryChecksum returns the checksum byte for a recovery key
+func recoveryChecksum(recoveryKey []byte) byte {
+	sum := sha256.Sum256(recoveryKey)
+	return sum[0]
+}
+
+// recoveryWordIndex looks up the byte value for a mnemonic word
+func recoveryWordIndex(word string) (byte, bool) {
+	for i, candidate := range recoveryWords {
+		if candidate == word {
+			return byte(i), true
+		}
+	}
+	return 0, false
+}
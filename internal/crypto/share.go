@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// x25519KeySize is the size of X25519 public and private keys in bytes
+const x25519KeySize = 32
+
+// GenerateKeyPair creates a new X25519 key pair for receiving shared pastes
+func GenerateKeyPair() (privateKey, publicKey []byte, err error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.Bytes(), key.PublicKey().Bytes(), nil
+}
+
+// SealToPublicKey encrypts data so only the holder of the matching private
+// key can read it. An ephemeral key pair is used for every call.
+func SealToPublicKey(data, recipientPublicKey []byte) (string, error) {
+	recipient, err := ecdh.X25519().NewPublicKey(recipientPublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	wrappingKey, err := deriveShareKey(ephemeral, recipient, ephemeral.PublicKey().Bytes(), recipientPublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := encrypt(data, wrappingKey)
+	if err != nil {
+		return "", err
+	}
+
+	sealed := append(ephemeral.PublicKey().Bytes(), encrypted...)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenWithPrivateKey decrypts data sealed with SealToPublicKey
+func OpenWithPrivateKey(sealedBase64 string, privateKey []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(sealedBase64)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < x25519KeySize {
+		return nil, errors.New("malformed sealed data")
+	}
+
+	own, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralPublicKey := sealed[:x25519KeySize]
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappingKey, err := deriveShareKey(own, ephemeral, ephemeralPublicKey, own.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return decrypt(sealed[x25519KeySize:], wrappingKey)
+}
+
+// PublicKeyFingerprint returns a short, human comparable fingerprint of a
+// public key, so users can check the server handed out the right key
+func PublicKeyFingerprint(publicKey []byte) string {
+	hash := sha256.Sum256(publicKey)
+	encoded := strings.ToUpper(hex.EncodeToString(hash[:16]))
+
+	groups := make([]string, 0, len(encoded)/4)
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:i+4])
+	}
+	return strings.Join(groups, " ")
+}
+
+// deriveShareKey turns an X25519 shared secret into an AES key, bound to both
+// public keys
+func deriveShareKey(private *ecdh.PrivateKey, public *ecdh.PublicKey, ephemeralPublicKey, recipientPublicKey []byte) ([]byte, error) {
+	shared, err := private.ECDH(public)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := append(append([]byte{}, ephemeralPublicKey...), recipientPublicKey...)
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte("pastepal-share")), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+// GenerateSigningKey creates a new Ed25519 key pair for signing pastes. The
+// private key is returned as its 32 byte seed.
+func GenerateSigningKey() (privateKey, publicKey []byte, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return private.Seed(), public, nil
+}
+
+// SignData signs data with an Ed25519 seed and returns the base64 signature
+func SignData(data, privateKey []byte) (string, error) {
+	if len(privateKey) != ed25519.SeedSize {
+		return "", errors.New("invalid signing key")
+	}
+
+	signature := ed25519.Sign(ed25519.NewKeyFromSeed(privateKey), data)
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// VerifySignature reports whether a base64 signature over data was made by
+// the given Ed25519 public key
+func VerifySignature(data []byte, signatureBase64 string, publicKey []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(signatureBase64)
+	if err != nil {
+		return false
+	}
+
+	return ed25519.Verify(ed25519.PublicKey(publicKey), data, signature)
+}
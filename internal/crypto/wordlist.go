@@ -0,0 +1,38 @@
+package crypto
+
+// recoveryWords maps each byte value to a word for recovery key mnemonics.
+// The list must never be reordered, or existing recovery keys stop decoding.
+var recoveryWords = [256]string{
+	"acid", "acorn", "actor", "adobe", "agent", "album", "alert", "alley",
+	"amber", "angle", "ankle", "apple", "april", "apron", "arena", "armor",
+	"arrow", "atlas", "attic", "audio", "autumn", "avenue", "badge", "bagel",
+	"baker", "bamboo", "banjo", "barn", "basil", "basket", "beach", "beacon",
+	"beard", "bench", "berry", "bison", "blade", "blanket", "blaze", "blossom",
+	"board", "bonus", "boots", "bottle", "brain", "brass", "bread", "brick",
+	"bridge", "broom", "brush", "bucket", "buffalo", "bugle", "butter", "cabin",
+	"cactus", "camel", "candle", "canoe", "canvas", "canyon", "carbon", "cargo",
+	"carpet", "castle", "cedar", "cellar", "chalk", "charm", "cherry", "chess",
+	"chimney", "cider", "cinema", "circus", "citrus", "clay", "cliff", "clock",
+	"cloud", "clover", "cobra", "cocoa", "comet", "coral", "cotton", "cowboy",
+	"crane", "crayon", "cricket", "crown", "crystal", "cupcake", "curtain", "cypress",
+	"daisy", "dawn", "delta", "denim", "desert", "diesel", "dinner", "dolphin",
+	"domino", "donkey", "dragon", "drum", "eagle", "easel", "echo", "eclipse",
+	"elbow", "ember", "emerald", "engine", "fabric", "falcon", "feather", "fennel",
+	"ferry", "fiddle", "finch", "flag", "flame", "flute", "forest", "fossil",
+	"fountain", "fox", "galaxy", "garden", "garlic", "gecko", "geyser", "ginger",
+	"glacier", "globe", "goblet", "gopher", "granite", "grape", "gravel", "guitar",
+	"hammer", "harbor", "harvest", "hazel", "helmet", "heron", "hickory", "honey",
+	"hornet", "igloo", "iris", "island", "ivory", "jacket", "jaguar", "jasmine",
+	"jelly", "jersey", "jigsaw", "jungle", "kayak", "kernel", "kettle", "kiwi",
+	"koala", "ladder", "lagoon", "lantern", "laser", "lemon", "lentil", "lilac",
+	"linen", "lizard", "lobster", "locket", "lotus", "lumber", "magnet", "mango",
+	"maple", "marble", "meadow", "melon", "meteor", "mirror", "mitten", "monkey",
+	"mosaic", "motor", "muffin", "mustard", "napkin", "nectar", "needle", "nickel",
+	"noodle", "nutmeg", "oasis", "ocean", "olive", "onion", "orbit", "orchid",
+	"otter", "oyster", "paddle", "panda", "parrot", "pebble", "pepper", "piano",
+	"pickle", "pigeon", "pillow", "pirate", "planet", "plum", "pocket", "pony",
+	"poppy", "potato", "prism", "puzzle", "quartz", "quill", "rabbit", "radar",
+	"radish", "raft", "raven", "ribbon", "rocket", "saddle", "salmon", "satin",
+	"scarf", "shovel", "silver", "sketch", "sled", "spider", "spruce", "squid",
+	"stable", "stamp", "summit", "sunset", "tiger", "tulip", "walnut", "zebra",
+}
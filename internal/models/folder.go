@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Folder groups a user's pastes. Folders nest through their parent, and
+// their names are encrypted like paste fields.
+type Folder struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	ParentID  string    `json:"parent_id,omitempty"` // Empty for top-level folders
+	Name      string    `json:"name"`                // Encrypted
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateFolderRequest represents a request to create a folder
+type CreateFolderRequest struct {
+	ID       string `json:"id,omitempty"` // Client chosen, so the name can be bound to it
+	ParentID string `json:"parent_id,omitempty"`
+	Name     string `json:"name"` // Already encrypted
+}
+
+// UpdateFolderRequest renames or moves a folder
+type UpdateFolderRequest struct {
+	ParentID string `json:"parent_id"`
+	Name     string `json:"name"` // Already encrypted
+}
+
+// MovePasteRequest moves a paste into a folder
+type MovePasteRequest struct {
+	FolderID string `json:"folder_id"` // Empty for the top level
+}
+
+// PasteTagsRequest replaces the tags of a paste
+type PasteTagsRequest struct {
+	Tags string `json:"tags"` // Already encrypted
+}
@@ -4,6 +4,31 @@ import (
 	"time"
 )
 
+// Paste encryption formats
+const (
+	// PasteEncryptionLegacy pastes are encrypted without associated data
+	PasteEncryptionLegacy = 0
+	// PasteEncryptionEnvelope pastes bind the owner, paste ID and field name
+	// into the associated data of every encrypted field, and wrap their
+	// title and content in an envelope that may be compressed
+	PasteEncryptionEnvelope = 2
+)
+
+// Paste signature formats
+const (
+	// PasteSignatureV2 signatures cover the author, the encrypted title and
+	// content, the visibility, the expiry, the access limit, the paste ID,
+	// the wrapped content key and the encrypted language and format
+	PasteSignatureV2 = 2
+)
+
+// Paste content formats, which decide how content is displayed
+const (
+	PasteFormatPlain    = "plain"
+	PasteFormatCode     = "code"
+	PasteFormatMarkdown = "markdown"
+)
+
 // Paste represents an encrypted paste stored on the server
 type Paste struct {
 	ID            string    `json:"id"`
@@ -15,15 +40,67 @@ type Paste struct {
 	IsPublic      bool      `json:"is_public"`
 	AccessCount   int       `json:"access_count,omitempty"`
 	MaxAccessCount int       `json:"max_access_count,omitempty"`
+	// Per-paste key wrapped with the owner's symmetric key. Empty for pastes
+	// created before per-paste keys, which are encrypted with the symmetric
+	// key directly.
+	EncryptedContentKey string `json:"encrypted_content_key,omitempty"`
+	// Optional Ed25519 signature by the author over the ciphertext and metadata
+	Signature string `json:"signature,omitempty"`
+	SignatureVersion int `json:"signature_version,omitempty"`
+	EncryptionVersion int `json:"encryption_version,omitempty"`
+	Language string `json:"language,omitempty"` // Encrypted, empty when unknown
+	Format   string `json:"format,omitempty"`   // Encrypted, empty when unknown
+	// Organization, private to the owner
+	Tags     string `json:"tags,omitempty"`      // Encrypted
+	FolderID string `json:"folder_id,omitempty"` // Empty for the top level
+	// When the paste was moved to the trash, zero for pastes not in it
+	DeletedAt time.Time `json:"deleted_at,omitempty"`
 }
 
 // CreatePasteRequest represents a request to create a new paste
 type CreatePasteRequest struct {
+	ID            string    `json:"id,omitempty"` // Client chosen, so fields can be bound to it
 	Title         string    `json:"title"` // Already encrypted
 	Content       string    `json:"content"` // Already encrypted
 	ExpiresAt     time.Time `json:"expires_at,omitempty"`
 	IsPublic      bool      `json:"is_public"`
 	MaxAccessCount int       `json:"max_access_count,omitempty"`
+	EncryptedContentKey string `json:"encrypted_content_key,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	SignatureVersion int `json:"signature_version,omitempty"`
+	EncryptionVersion int `json:"encryption_version,omitempty"`
+	Language string `json:"language,omitempty"` // Already encrypted
+	Format   string `json:"format,omitempty"`   // Already encrypted
+	Tags     string `json:"tags,omitempty"` // Already encrypted
+	FolderID string `json:"folder_id,omitempty"`
+}
+
+// UpdatePasteRequest replaces the encrypted fields of an existing paste
+type UpdatePasteRequest struct {
+	Title               string `json:"title"`   // Already encrypted
+	Content             string `json:"content"` // Already encrypted
+	EncryptedContentKey string `json:"encrypted_content_key,omitempty"`
+	Signature           string `json:"signature,omitempty"`
+	SignatureVersion    int    `json:"signature_version,omitempty"`
+	EncryptionVersion   int    `json:"encryption_version"`
+	Language            string `json:"language,omitempty"` // Already encrypted
+	Format              string `json:"format,omitempty"`   // Already encrypted
+}
+
+// ShareRequest grants another user access to a paste by giving them its
+// content key sealed to their public key
+type ShareRequest struct {
+	RecipientID  string `json:"recipient_id"`
+	EncryptedKey string `json:"encrypted_key"`
+}
+
+// SharedPaste is a paste another user has shared with the current user
+type SharedPaste struct {
+	Paste        Paste     `json:"paste"`
+	OwnerID      string    `json:"owner_id"`
+	OwnerEmail   string    `json:"owner_email"`
+	EncryptedKey string    `json:"encrypted_key"` // Content key sealed to our public key
+	SharedAt     time.Time `json:"shared_at"`
 }
 
 // PasteMetadata contains non-sensitive metadata about a paste
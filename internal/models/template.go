@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// Template is a paste template stored on the server. Everything about it,
+// down to its name, is encrypted together in Data.
+type Template struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Data      string    `json:"data"` // Encrypted
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateTemplateRequest represents a request to create a template
+type CreateTemplateRequest struct {
+	ID   string `json:"id,omitempty"` // Client chosen, so the data can be bound to it
+	Data string `json:"data"`         // Already encrypted
+}
+
+// UpdateTemplateRequest replaces the encrypted data of a template
+type UpdateTemplateRequest struct {
+	Data string `json:"data"` // Already encrypted
+}
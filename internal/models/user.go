@@ -8,13 +8,23 @@ type User struct {
 	Email                string    `json:"email"`
 	EncryptedSymmetricKey string    `json:"encrypted_symmetric_key"`
 	CreatedAt            time.Time `json:"created_at,omitempty"`
+	TwoFactorEnabled     bool      `json:"two_factor_enabled,omitempty"`
+	HasRecoveryKey       bool      `json:"has_recovery_key,omitempty"`
+	PublicKey            string    `json:"public_key,omitempty"`
+	SigningPublicKey     string    `json:"signing_public_key,omitempty"`
 }
 
 // RegistrationData contains the data needed to register a user
 type RegistrationData struct {
 	Email                string `json:"email"`
-	PasswordHash         string `json:"password_hash"`
+	PasswordHash         string `json:"password_hash,omitempty"` // Legacy servers only
 	EncryptedSymmetricKey string `json:"encrypted_symmetric_key"`
+	// Set instead of PasswordHash on servers that support SRP
+	SRP *SRPVerifierData `json:"srp,omitempty"`
+	// Optional account recovery
+	Recovery *RecoveryKeyData `json:"recovery,omitempty"`
+	// Identity for receiving shared pastes
+	KeyPair *KeyPairData `json:"key_pair,omitempty"`
 }
 
 // LoginRequest contains the data needed to authenticate a user
@@ -25,9 +35,11 @@ type LoginRequest struct {
 
 // UserResponse represents the user object in server responses
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID               string    `json:"id"`
+	Email            string    `json:"email"`
+	CreatedAt        time.Time `json:"created_at"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled,omitempty"`
+	HasRecoveryKey   bool      `json:"has_recovery_key,omitempty"`
 }
 
 // LoginResponse contains the server's response to a login request
@@ -35,8 +47,132 @@ type LoginResponse struct {
 	User                 UserResponse `json:"user"`
 	AuthToken            string       `json:"auth_token"`
 	EncryptedSymmetricKey string       `json:"encrypted_symmetric_key"`
+	// SRP server proof M2, only present on SRP logins
+	ServerProof string `json:"server_proof,omitempty"`
+	// Missing for accounts created before sharing existed
+	KeyPair *KeyPairData `json:"key_pair,omitempty"`
 	// Keep these for backward compatibility
 	Success bool   `json:"success,omitempty"`
 	Message string `json:"message,omitempty"`
 	UserID  string `json:"user_id,omitempty"`
 }
+
+// ProtocolsResponse lists the login protocols a server supports
+type ProtocolsResponse struct {
+	LoginProtocols []string `json:"login_protocols"`
+}
+
+// SRPVerifierData is stored by the server in place of a password hash
+type SRPVerifierData struct {
+	Salt     string `json:"salt"`     // Base64
+	Verifier string `json:"verifier"` // Base64, big-endian and padded to the group size
+}
+
+// SRPInitRequest starts an SRP login with the client's public ephemeral A
+type SRPInitRequest struct {
+	Email string `json:"email"`
+	A     string `json:"a"`
+}
+
+// SRPInitResponse carries the user's salt and the server's public ephemeral B
+type SRPInitResponse struct {
+	SessionID string `json:"session_id"`
+	Salt      string `json:"salt"`
+	B         string `json:"b"`
+}
+
+// SRPVerifyRequest sends the client proof M1
+type SRPVerifyRequest struct {
+	SessionID string `json:"session_id"`
+	M1        string `json:"m1"`
+}
+
+// TwoFactorChallenge is returned instead of a LoginResponse when the account
+// requires a second factor
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+}
+
+// TwoFactorLoginRequest completes a login that was answered with a challenge.
+// Exactly one of Code and RecoveryCode is set.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code,omitempty"`
+	RecoveryCode   string `json:"recovery_code,omitempty"`
+}
+
+// TwoFactorSetupResponse contains a new, not yet active TOTP secret
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"` // Base32 encoded
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest carries a TOTP or recovery code to confirm a 2FA change
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// TwoFactorEnableResponse contains the one-time recovery codes, which the
+// server only returns once
+type TwoFactorEnableResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RecoveryKeyData is what the server stores for account recovery: the
+// symmetric key wrapped with the recovery key, and a hash proving possession
+// of the recovery key
+type RecoveryKeyData struct {
+	RecoveryAuthHash              string `json:"recovery_auth_hash"`
+	RecoveryEncryptedSymmetricKey string `json:"recovery_encrypted_symmetric_key"`
+}
+
+// RecoveryRequest starts an account recovery. Accounts with 2FA must also
+// send a TOTP or 2FA recovery code.
+type RecoveryRequest struct {
+	Email            string `json:"email"`
+	RecoveryAuthHash string `json:"recovery_auth_hash"`
+	Code             string `json:"code,omitempty"`
+	RecoveryCode     string `json:"recovery_code,omitempty"`
+}
+
+// RecoveryResponse returns the recovery-wrapped symmetric key and a
+// short-lived token for setting the new password
+type RecoveryResponse struct {
+	RecoveryToken                 string `json:"recovery_token"`
+	RecoveryEncryptedSymmetricKey string `json:"recovery_encrypted_symmetric_key"`
+}
+
+// RecoveryResetRequest sets a new master password after recovery
+type RecoveryResetRequest struct {
+	RecoveryToken         string           `json:"recovery_token"`
+	PasswordHash          string           `json:"password_hash,omitempty"` // Legacy servers only
+	SRP                   *SRPVerifierData `json:"srp,omitempty"`
+	EncryptedSymmetricKey string           `json:"encrypted_symmetric_key"`
+}
+
+// KeyPairData is a user's X25519 sharing identity and Ed25519 signing key.
+// The private keys are wrapped with the user's symmetric key; the public keys
+// are published so others can share pastes with the user and verify theirs.
+type KeyPairData struct {
+	PublicKey           string `json:"public_key"`            // Base64
+	EncryptedPrivateKey string `json:"encrypted_private_key"` // Base64, encrypted
+	// Missing for identities created before signed pastes
+	SigningPublicKey    string `json:"signing_public_key,omitempty"`    // Base64
+	EncryptedSigningKey string `json:"encrypted_signing_key,omitempty"` // Base64, encrypted
+}
+
+// PublicKeyInfo is another user's published public keys
+type PublicKeyInfo struct {
+	UserID           string `json:"user_id"`
+	Email            string `json:"email"`
+	PublicKey        string `json:"public_key"`                   // Base64
+	SigningPublicKey string `json:"signing_public_key,omitempty"` // Base64
+}
+
+// AccountSettingsData holds a user's account settings, encrypted with their
+// symmetric key so the server can't read them
+type AccountSettingsData struct {
+	Data string `json:"data"` // Base64, encrypted
+}
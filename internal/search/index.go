@@ -0,0 +1,331 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mode selects how a query is matched against pastes
+type Mode int
+
+const (
+	// ModeSubstring matches the query anywhere in the text, ignoring case
+	ModeSubstring Mode = iota
+	// ModeWord matches pastes containing every word of the query
+	ModeWord
+	// ModeRegex matches a regular expression
+	ModeRegex
+)
+
+// String returns the name of the mode as shown to users
+func (m Mode) String() string {
+	switch m {
+	case ModeWord:
+		return "Words"
+	case ModeRegex:
+		return "Regex"
+	default:
+		return "Text"
+	}
+}
+
+// snippetRadius is how many characters are kept around a match in snippets
+const snippetRadius = 40
+
+// Document is the decrypted text of a paste
+type Document struct {
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	// Version identifies the encrypted paste the text was taken from, so
+	// the index can tell when the paste has changed since
+	Version string `json:"version,omitempty"`
+}
+
+// Result is a paste matching a query
+type Result struct {
+	PasteID   string
+	Title     string
+	Snippet   string
+	CreatedAt time.Time
+}
+
+// Index is an in-memory full-text index of decrypted pastes. It holds
+// plaintext, so it must only be persisted encrypted.
+type Index struct {
+	documents map[string]*Document
+	// Word -> IDs of the pastes containing it, rebuilt when loading
+	words map[string]map[string]struct{}
+	mutex sync.RWMutex
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		documents: make(map[string]*Document),
+		words:     make(map[string]map[string]struct{}),
+	}
+}
+
+// Load restores an index serialized with Marshal
+func Load(data []byte) (*Index, error) {
+	var documents map[string]*Document
+	if err := json.Unmarshal(data, &documents); err != nil {
+		return nil, err
+	}
+
+	idx := NewIndex()
+	for id, doc := range documents {
+		idx.put(id, doc)
+	}
+	return idx, nil
+}
+
+// Marshal serializes the indexed documents
+func (idx *Index) Marshal() ([]byte, error) {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	return json.Marshal(idx.documents)
+}
+
+// Put adds a paste to the index, replacing an earlier version of it
+func (idx *Index) Put(id string, doc *Document) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.remove(id)
+	idx.put(id, doc)
+}
+
+// Remove drops a paste from the index
+func (idx *Index) Remove(id string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.remove(id)
+}
+
+// Version returns the version of an indexed paste, and whether it is
+// indexed at all
+func (idx *Index) Version(id string) (string, bool) {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	doc, ok := idx.documents[id]
+	if !ok {
+		return "", false
+	}
+	return doc.Version, true
+}
+
+// IDs returns the IDs of all indexed pastes
+func (idx *Index) IDs() []string {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	ids := make([]string, 0, len(idx.documents))
+	for id := range idx.documents {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (idx *Index) put(id string, doc *Document) {
+	idx.documents[id] = doc
+	for _, word := range tokenize(doc.Title + " " + doc.Content) {
+		ids, ok := idx.words[word]
+		if !ok {
+			ids = make(map[string]struct{})
+			idx.words[word] = ids
+		}
+		ids[id] = struct{}{}
+	}
+}
+
+func (idx *Index) remove(id string) {
+	doc, ok := idx.documents[id]
+	if !ok {
+		return
+	}
+
+	for _, word := range tokenize(doc.Title + " " + doc.Content) {
+		if ids, ok := idx.words[word]; ok {
+			delete(ids, id)
+			if len(ids) == 0 {
+				delete(idx.words, word)
+			}
+		}
+	}
+	delete(idx.documents, id)
+}
+
+// Search returns the pastes matching a query, those matching in the title
+// first and newest first after that
+func (idx *Index) Search(query string, mode Mode) ([]Result, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("empty search query")
+	}
+
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	var match func(text string) (int, int)
+	candidates := idx.documents
+
+	switch mode {
+	case ModeSubstring:
+		needle := strings.ToLower(query)
+		match = func(text string) (int, int) {
+			// Lower-casing can change byte offsets, so only the start is
+			// taken from the folded text when the lengths agree
+			folded := strings.ToLower(text)
+			i := strings.Index(folded, needle)
+			if i < 0 {
+				return -1, -1
+			}
+			if len(folded) != len(text) {
+				return 0, 0
+			}
+			return i, i + len(needle)
+		}
+	case ModeWord:
+		words := tokenize(query)
+		if len(words) == 0 {
+			return nil, errors.New("search query has no words")
+		}
+		candidates = idx.withWords(words)
+		pattern := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(words[0]) + `\b`)
+		match = func(text string) (int, int) {
+			loc := pattern.FindStringIndex(text)
+			if loc == nil {
+				return -1, -1
+			}
+			return loc[0], loc[1]
+		}
+	case ModeRegex:
+		pattern, err := regexp.Compile(query)
+		if err != nil {
+			return nil, err
+		}
+		match = func(text string) (int, int) {
+			loc := pattern.FindStringIndex(text)
+			if loc == nil {
+				return -1, -1
+			}
+			return loc[0], loc[1]
+		}
+	default:
+		return nil, errors.New("unknown search mode")
+	}
+
+	type ranked struct {
+		Result
+		inTitle bool
+	}
+
+	var matches []ranked
+	for id, doc := range candidates {
+		titleStart, _ := match(doc.Title)
+		contentStart, contentEnd := match(doc.Content)
+
+		// Word mode candidates already contain every word somewhere
+		if titleStart < 0 && contentStart < 0 && mode != ModeWord {
+			continue
+		}
+
+		// Title matches show the start of the content instead
+		if contentStart < 0 {
+			contentStart, contentEnd = 0, 0
+		}
+		snippet := makeSnippet(doc.Content, contentStart, contentEnd)
+
+		matches = append(matches, ranked{
+			Result: Result{
+				PasteID:   id,
+				Title:     doc.Title,
+				Snippet:   snippet,
+				CreatedAt: doc.CreatedAt,
+			},
+			inTitle: titleStart >= 0,
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].inTitle != matches[j].inTitle {
+			return matches[i].inTitle
+		}
+		return matches[i].CreatedAt.After(matches[j].CreatedAt)
+	})
+
+	results := make([]Result, len(matches))
+	for i, m := range matches {
+		results[i] = m.Result
+	}
+	return results, nil
+}
+
+// withWords returns the documents containing every one of the words
+func (idx *Index) withWords(words []string) map[string]*Document {
+	result := make(map[string]*Document)
+
+	first, ok := idx.words[words[0]]
+	if !ok {
+		return result
+	}
+
+next:
+	for id := range first {
+		for _, word := range words[1:] {
+			if _, ok := idx.words[word][id]; !ok {
+				continue next
+			}
+		}
+		result[id] = idx.documents[id]
+	}
+	return result
+}
+
+// tokenize splits text into lower-case words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// makeSnippet cuts the text around a match down to a single short line
+func makeSnippet(text string, start, end int) string {
+	from := start - snippetRadius
+	if from < 0 {
+		from = 0
+	}
+	to := end + snippetRadius
+	if to > len(text) {
+		to = len(text)
+	}
+
+	// Don't cut runes in half
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+
+	snippet := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
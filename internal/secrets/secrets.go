@@ -0,0 +1,174 @@
+// Package secrets finds likely secrets in text, such as cloud credentials,
+// private keys and tokens, so they can be redacted before a paste is made
+// readable by others. It runs entirely on the client: text is never sent
+// anywhere to be checked.
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is a kind of secret
+type Kind string
+
+// Kinds of secrets that are detected
+const (
+	KindPrivateKey   Kind = "private key"
+	KindAWSAccessKey Kind = "AWS access key ID"
+	KindAWSSecretKey Kind = "AWS secret access key"
+	KindJWT          Kind = "JSON Web Token"
+	KindHighEntropy  Kind = "high-entropy string"
+)
+
+// Finding is a likely secret in a text
+type Finding struct {
+	Kind Kind
+	// Byte offsets of the secret in the text
+	Start, End int
+	// Line the secret starts on, counting from 1
+	Line int
+}
+
+// detector finds one kind of secret. When the pattern has a group, only the
+// group is the secret.
+type detector struct {
+	kind    Kind
+	pattern *regexp.Regexp
+}
+
+// Detectors, most specific first. A PEM block without its end line is
+// taken to run to the end of the text.
+var detectors = []detector{
+	{KindPrivateKey, regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----(?:.*?-----END [A-Z0-9 ]*PRIVATE KEY-----|.*)`)},
+	{KindAWSAccessKey, regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+	{KindAWSSecretKey, regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|key).{0,20}?[\s'"=:]([A-Za-z0-9/+]{40})(?:[^A-Za-z0-9/+=]|$)`)},
+	{KindJWT, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]*`)},
+}
+
+// candidatePattern matches runs of characters that tokens and keys are made
+// of, to be checked for high entropy
+var candidatePattern = regexp.MustCompile(`[A-Za-z0-9+/_=-]{20,}`)
+
+// Entropy thresholds in bits per character. Hex has at most 4, so it gets
+// a lower bar but has to be longer.
+const (
+	minEntropy     = 4.0
+	minHexEntropy  = 3.0
+	minHexLength   = 32
+	minTokenLength = 20
+)
+
+// Scan returns the likely secrets in text, in the order they appear. The
+// findings don't overlap.
+func Scan(text string) []Finding {
+	var findings []Finding
+	overlaps := func(start, end int) bool {
+		for _, f := range findings {
+			if start < f.End && f.Start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, d := range detectors {
+		for _, match := range d.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := match[0], match[1]
+			if len(match) > 2 {
+				start, end = match[2], match[3]
+			}
+			if !overlaps(start, end) {
+				findings = append(findings, Finding{Kind: d.kind, Start: start, End: end})
+			}
+		}
+	}
+
+	for _, match := range candidatePattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[1]
+		if highEntropy(strings.TrimRight(text[start:end], "=")) && !overlaps(start, end) {
+			findings = append(findings, Finding{Kind: KindHighEntropy, Start: start, End: end})
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Start < findings[j].Start
+	})
+	for i := range findings {
+		findings[i].Line = strings.Count(text[:findings[i].Start], "\n") + 1
+	}
+	return findings
+}
+
+// highEntropy reports whether a token looks random enough to be a key.
+// Tokens without both letters and digits are taken to be words or numbers.
+func highEntropy(token string) bool {
+	if len(token) < minTokenLength {
+		return false
+	}
+	if !strings.ContainsAny(token, "0123456789") || strings.Trim(token, "0123456789") == "" {
+		return false
+	}
+
+	if strings.Trim(strings.ToLower(token), "0123456789abcdef") == "" {
+		return len(token) >= minHexLength && entropy(token) >= minHexEntropy
+	}
+	return entropy(token) >= minEntropy
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	n := float64(utf8.RuneCountInString(s))
+	var bits float64
+	for _, count := range counts {
+		p := float64(count) / n
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
+
+// Redact replaces the findings in text, which must come from scanning that
+// text, with a note of what was removed
+func Redact(text string, findings []Finding) string {
+	var b strings.Builder
+	last := 0
+	for _, f := range findings {
+		b.WriteString(text[last:f.Start])
+		b.WriteString("[REDACTED " + string(f.Kind) + "]")
+		last = f.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Describe lists findings in text one per line for a warning, showing just
+// enough of each secret to recognize it
+func Describe(text string, findings []Finding) []string {
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = fmt.Sprintf("line %d: %s (%s)", f.Line, f.Kind, mask(text[f.Start:f.End]))
+	}
+	return lines
+}
+
+// mask shows the first few characters of a secret and its length
+func mask(secret string) string {
+	const shown = 4
+
+	length := utf8.RuneCountInString(secret)
+	if length <= shown*2 {
+		return fmt.Sprintf("%d characters", length)
+	}
+
+	prefix := []rune(secret)[:shown]
+	return fmt.Sprintf("%s…, %d characters", string(prefix), length)
+}
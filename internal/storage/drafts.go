@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extension of draft files
+const draftExt = ".draft"
+
+// errInvalidDraftID is returned for a draft ID that isn't hex, which could
+// otherwise be used as a path
+var errInvalidDraftID = errors.New("invalid draft ID")
+
+// draftsDir returns where a user's drafts are kept, named after a hash of
+// the user ID like the search index
+func (ls *LocalStorage) draftsDir(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return filepath.Join(ls.basePath, "users", "drafts", hex.EncodeToString(sum[:]))
+}
+
+// draftPath returns the file of one of a user's drafts
+func (ls *LocalStorage) draftPath(userID, draftID string) (string, error) {
+	if _, err := hex.DecodeString(draftID); err != nil || draftID == "" {
+		return "", errInvalidDraftID
+	}
+	return filepath.Join(ls.draftsDir(userID), draftID+draftExt), nil
+}
+
+// SaveDraft stores one of a user's drafts, replacing an earlier save of it.
+// Drafts hold plaintext, so callers must encrypt them first.
+func (ls *LocalStorage) SaveDraft(userID, draftID, encryptedDraft string) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	draftPath, err := ls.draftPath(userID, draftID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(draftPath), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so a crash mid-save keeps the
+	// previous save
+	tmpPath := draftPath + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(encryptedDraft), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, draftPath)
+}
+
+// GetDrafts retrieves a user's encrypted drafts by draft ID
+func (ls *LocalStorage) GetDrafts(userID string) (map[string]string, error) {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+
+	dir := ls.draftsDir(userID)
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	drafts := make(map[string]string, len(files))
+	for _, file := range files {
+		draftID, ok := strings.CutSuffix(file.Name(), draftExt)
+		if file.IsDir() || !ok {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		drafts[draftID] = string(data)
+	}
+
+	return drafts, nil
+}
+
+// DeleteDraft removes one of a user's drafts if it exists
+func (ls *LocalStorage) DeleteDraft(userID, draftID string) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	draftPath, err := ls.draftPath(userID, draftID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(draftPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -6,13 +6,15 @@ import (
 	"github.com/JacobRWebb/PastePal-OS/internal/models"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 )
 
 // LocalStorage handles local storage of user data
 type LocalStorage struct {
-	basePath string
-	mutex    sync.RWMutex
+	basePath       string
+	cacheMaxPastes int // 0 means unlimited
+	mutex          sync.RWMutex
 }
 
 // NewLocalStorage creates a new local storage instance
@@ -22,10 +24,77 @@ func NewLocalStorage(basePath string) (*LocalStorage, error) {
 		return nil, err
 	}
 
-	return &LocalStorage{
+	ls := &LocalStorage{
 		basePath: basePath,
 		mutex:    sync.RWMutex{},
-	}, nil
+	}
+
+	if err := ls.scrubLegacyCredentials(); err != nil {
+		return nil, err
+	}
+
+	return ls, nil
+}
+
+// SetCacheLimit sets the maximum number of pastes kept in the local cache
+// and evicts the oldest cached pastes beyond it
+func (ls *LocalStorage) SetCacheLimit(maxPastes int) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	ls.cacheMaxPastes = maxPastes
+	return ls.pruneCache()
+}
+
+// pruneCache removes the least recently written pastes over the cache limit.
+// Callers must hold the write lock.
+func (ls *LocalStorage) pruneCache() error {
+	if ls.cacheMaxPastes <= 0 {
+		return nil
+	}
+
+	pastesDir := filepath.Join(ls.basePath, "pastes")
+	files, err := os.ReadDir(pastesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	type cachedFile struct {
+		name    string
+		modTime int64
+	}
+
+	cached := make([]cachedFile, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		cached = append(cached, cachedFile{name: file.Name(), modTime: info.ModTime().UnixNano()})
+	}
+
+	if len(cached) <= ls.cacheMaxPastes {
+		return nil
+	}
+
+	// Oldest first
+	sort.Slice(cached, func(i, j int) bool {
+		return cached[i].modTime < cached[j].modTime
+	})
+
+	for _, file := range cached[:len(cached)-ls.cacheMaxPastes] {
+		if err := os.Remove(filepath.Join(pastesDir, file.name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // SaveUserSession saves user session data locally
@@ -56,8 +125,9 @@ func (ls *LocalStorage) SaveUserSession(email string, symmetricKey []byte) error
 	return os.WriteFile(filepath.Join(userDir, "session.json"), data, 0600)
 }
 
-// SaveCredentials saves user credentials for the remember me feature
-func (ls *LocalStorage) SaveCredentials(email, passwordHash string, rememberMe bool) error {
+// SaveCredentials remembers the user's email for the remember me feature.
+// Only the email is stored; nothing that could be replayed to log in.
+func (ls *LocalStorage) SaveCredentials(email string, rememberMe bool) error {
 	ls.mutex.Lock()
 	defer ls.mutex.Unlock()
 
@@ -79,8 +149,7 @@ func (ls *LocalStorage) SaveCredentials(email, passwordHash string, rememberMe b
 
 	// Save credentials
 	credentialsData := map[string]string{
-		"email":        email,
-		"passwordHash": passwordHash,
+		"email": email,
 	}
 
 	data, err := json.Marshal(credentialsData)
@@ -91,33 +160,54 @@ func (ls *LocalStorage) SaveCredentials(email, passwordHash string, rememberMe b
 	return os.WriteFile(filepath.Join(userDir, "credentials.json"), data, 0600)
 }
 
-// GetSavedCredentials retrieves saved credentials if they exist
-func (ls *LocalStorage) GetSavedCredentials() (email, passwordHash string, err error) {
+// GetSavedCredentials retrieves the remembered email if there is one
+func (ls *LocalStorage) GetSavedCredentials() (string, error) {
 	ls.mutex.RLock()
 	defer ls.mutex.RUnlock()
 
 	credPath := filepath.Join(ls.basePath, "users", "credentials.json")
 	data, err := os.ReadFile(credPath)
 	if err != nil {
-		return "", "", errors.New("no saved credentials")
+		return "", errors.New("no saved credentials")
 	}
 
 	var credentialsData map[string]string
 	if err := json.Unmarshal(data, &credentialsData); err != nil {
-		return "", "", err
+		return "", err
 	}
 
 	email, ok := credentialsData["email"]
 	if !ok {
-		return "", "", errors.New("invalid credentials data: missing email")
+		return "", errors.New("invalid credentials data: missing email")
 	}
 
-	passwordHash, ok = credentialsData["passwordHash"]
-	if !ok {
-		return "", "", errors.New("invalid credentials data: missing password hash")
+	return email, nil
+}
+
+// scrubLegacyCredentials rewrites credentials saved by older versions, which
+// stored a replayable password hash next to the email
+func (ls *LocalStorage) scrubLegacyCredentials() error {
+	credPath := filepath.Join(ls.basePath, "users", "credentials.json")
+	data, err := os.ReadFile(credPath)
+	if err != nil {
+		return nil
 	}
 
-	return email, passwordHash, nil
+	var credentialsData map[string]string
+	if err := json.Unmarshal(data, &credentialsData); err != nil {
+		return os.Remove(credPath)
+	}
+
+	if _, ok := credentialsData["passwordHash"]; !ok {
+		return nil
+	}
+
+	data, err = json.Marshal(map[string]string{"email": credentialsData["email"]})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(credPath, data, 0600)
 }
 
 // GetUserSession retrieves the current user session
@@ -158,6 +248,52 @@ func (ls *LocalStorage) ClearUserSession() error {
 	return nil
 }
 
+// SetPastesUpgraded records that all of a user's pastes were upgraded to the
+// current encryption format
+func (ls *LocalStorage) SetPastesUpgraded(userID string) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	userDir := filepath.Join(ls.basePath, "users")
+	if err := os.MkdirAll(userDir, 0700); err != nil {
+		return err
+	}
+
+	upgraded := ls.readUpgradedUsers()
+	upgraded[userID] = true
+
+	data, err := json.Marshal(upgraded)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(userDir, "upgraded.json"), data, 0600)
+}
+
+// PastesUpgraded reports whether SetPastesUpgraded was called for a user
+func (ls *LocalStorage) PastesUpgraded(userID string) bool {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+
+	return ls.readUpgradedUsers()[userID]
+}
+
+// readUpgradedUsers loads the users whose pastes were upgraded. Callers must
+// hold the lock.
+func (ls *LocalStorage) readUpgradedUsers() map[string]bool {
+	upgraded := make(map[string]bool)
+
+	data, err := os.ReadFile(filepath.Join(ls.basePath, "users", "upgraded.json"))
+	if err != nil {
+		return upgraded
+	}
+
+	if err := json.Unmarshal(data, &upgraded); err != nil {
+		return make(map[string]bool)
+	}
+	return upgraded
+}
+
 // SavePasteLocally saves a paste to local storage
 func (ls *LocalStorage) SavePasteLocally(paste *models.Paste) error {
 	ls.mutex.Lock()
@@ -174,7 +310,27 @@ func (ls *LocalStorage) SavePasteLocally(paste *models.Paste) error {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(pastesDir, paste.ID+".json"), data, 0600)
+	if err := os.WriteFile(filepath.Join(pastesDir, paste.ID+".json"), data, 0600); err != nil {
+		return err
+	}
+
+	return ls.pruneCache()
+}
+
+// DeletePasteLocally removes a paste from the local cache if it is there
+func (ls *LocalStorage) DeletePasteLocally(pasteID string) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	if pasteID == "" || filepath.Base(pasteID) != pasteID {
+		return errors.New("invalid paste ID")
+	}
+
+	if err := os.Remove(filepath.Join(ls.basePath, "pastes", pasteID+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
 }
 
 // GetLocalPastes retrieves all locally saved pastes
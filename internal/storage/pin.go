@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrNoPIN is returned when no PIN has been set up
+var ErrNoPIN = errors.New("no PIN set up")
+
+// PINRecord is the symmetric key wrapped with a key derived from a PIN. It
+// is only ever stored on this device.
+type PINRecord struct {
+	UserID     string `json:"user_id"`
+	Salt       string `json:"salt"`        // Base64
+	WrappedKey string `json:"wrapped_key"` // Base64, encrypted with the PIN key
+	// Argon2id parameters the PIN key was derived with
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+	// FailedAttempts counts wrong PINs since the last successful unlock
+	FailedAttempts int `json:"failed_attempts"`
+	// CreatedAt is when the PIN was set up. It stops working a fixed time
+	// later, however often the master password is entered meanwhile.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SavePIN stores the PIN record, replacing any previous one
+func (ls *LocalStorage) SavePIN(record *PINRecord) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	userDir := filepath.Join(ls.basePath, "users")
+	if err := os.MkdirAll(userDir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(userDir, "pin.json"), data, 0600)
+}
+
+// GetPIN retrieves the PIN record
+func (ls *LocalStorage) GetPIN() (*PINRecord, error) {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(ls.basePath, "users", "pin.json"))
+	if err != nil {
+		return nil, ErrNoPIN
+	}
+
+	var record PINRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// DeletePIN removes the PIN record if there is one
+func (ls *LocalStorage) DeletePIN() error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	pinPath := filepath.Join(ls.basePath, "users", "pin.json")
+	if err := os.Remove(pinPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
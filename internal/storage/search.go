@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// searchIndexPath returns where a user's search index is kept. The file is
+// named after a hash of the user ID so it can't be used as a path.
+func (ls *LocalStorage) searchIndexPath(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return filepath.Join(ls.basePath, "users", "search", hex.EncodeToString(sum[:])+".idx")
+}
+
+// SaveSearchIndex stores a user's search index. The index holds plaintext,
+// so callers must encrypt it first.
+func (ls *LocalStorage) SaveSearchIndex(userID, encryptedIndex string) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	indexPath := ls.searchIndexPath(userID)
+	if err := os.MkdirAll(filepath.Dir(indexPath), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so a crash can't leave half an index
+	tmpPath := indexPath + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(encryptedIndex), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, indexPath)
+}
+
+// GetSearchIndex retrieves a user's encrypted search index. It returns an
+// empty string when there is none yet.
+func (ls *LocalStorage) GetSearchIndex(userID string) (string, error) {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+
+	data, err := os.ReadFile(ls.searchIndexPath(userID))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// DeleteSearchIndex removes a user's search index if there is one
+func (ls *LocalStorage) DeleteSearchIndex(userID string) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	if err := os.Remove(ls.searchIndexPath(userID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// srpAccountKey names an account in srp.json by a hash of its email, so the
+// file doesn't list who has used this device
+func srpAccountKey(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetSRPEnrolled records that an account has logged in with SRP on this
+// device, so it is never downgraded to a password hash login again
+func (ls *LocalStorage) SetSRPEnrolled(email string) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	userDir := filepath.Join(ls.basePath, "users")
+	if err := os.MkdirAll(userDir, 0700); err != nil {
+		return err
+	}
+
+	enrolled := ls.readSRPAccounts()
+	enrolled[srpAccountKey(email)] = true
+
+	data, err := json.Marshal(enrolled)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(userDir, "srp.json"), data, 0600)
+}
+
+// SRPEnrolled reports whether SetSRPEnrolled was called for an account
+func (ls *LocalStorage) SRPEnrolled(email string) bool {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+
+	return ls.readSRPAccounts()[srpAccountKey(email)]
+}
+
+// readSRPAccounts loads the accounts known to use SRP. Callers must hold the
+// lock.
+func (ls *LocalStorage) readSRPAccounts() map[string]bool {
+	enrolled := make(map[string]bool)
+
+	data, err := os.ReadFile(filepath.Join(ls.basePath, "users", "srp.json"))
+	if err != nil {
+		return enrolled
+	}
+
+	if err := json.Unmarshal(data, &enrolled); err != nil {
+		return make(map[string]bool)
+	}
+	return enrolled
+}
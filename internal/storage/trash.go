@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// trashSeenPath returns where the times a user's pastes were first seen in
+// the trash are kept, named after a hash of the user ID like the search
+// index
+func (ls *LocalStorage) trashSeenPath(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return filepath.Join(ls.basePath, "users", "trash", hex.EncodeToString(sum[:])+".json")
+}
+
+// NoteTrashed records when each of the given pastes was first seen in a
+// user's trash, for pastes the server didn't say the deletion time of, and
+// returns those times. Pastes that are no longer in the trash are
+// forgotten.
+func (ls *LocalStorage) NoteTrashed(userID string, pasteIDs []string) (map[string]time.Time, error) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	seenPath := ls.trashSeenPath(userID)
+
+	previous := make(map[string]time.Time)
+	if data, err := os.ReadFile(seenPath); err == nil {
+		if err := json.Unmarshal(data, &previous); err != nil {
+			previous = make(map[string]time.Time)
+		}
+	}
+
+	now := time.Now()
+	seen := make(map[string]time.Time, len(pasteIDs))
+	changed := len(previous) != len(pasteIDs)
+	for _, id := range pasteIDs {
+		at, ok := previous[id]
+		if !ok {
+			at = now
+			changed = true
+		}
+		seen[id] = at
+	}
+	if !changed {
+		return seen, nil
+	}
+
+	if len(seen) == 0 {
+		if err := os.Remove(seenPath); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return seen, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(seenPath), 0700); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(seen)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(seenPath, data, 0600); err != nil {
+		return nil, err
+	}
+	return seen, nil
+}
@@ -0,0 +1,209 @@
+package syntax
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// Languages with a meaning of their own
+const (
+	// PlainText is the language of pastes that aren't code
+	PlainText = "plaintext"
+	// Markdown pastes can be rendered rather than highlighted
+	Markdown = "markdown"
+)
+
+// CommonLanguages are offered first when picking a language. Any language
+// Lookup knows is accepted.
+var CommonLanguages = []string{
+	"Bash", "C", "C#", "C++", "CSS", "Diff", "Docker", "Go", "HTML", "INI",
+	"Java", "JavaScript", "JSON", "Kotlin", "Lua", "Makefile", "markdown",
+	"Nginx configuration file", "PHP", "PowerShell", "Python", "Ruby", "Rust",
+	"SQL", "Swift", "TOML", "TypeScript", "XML", "YAML",
+}
+
+// Lookup returns the canonical name of a language, matching its name or an
+// alias case-insensitively. It returns "" for unknown languages.
+func Lookup(language string) string {
+	if strings.TrimSpace(language) == "" {
+		return ""
+	}
+
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		return ""
+	}
+	return lexer.Config().Name
+}
+
+// Extension returns the usual file extension of a language, including the
+// dot. Languages without one, and unknown languages, get ".txt".
+func Extension(language string) string {
+	if lexer := lexers.Get(language); lexer != nil {
+		for _, pattern := range lexer.Config().Filenames {
+			ext := strings.TrimPrefix(pattern, "*")
+			if strings.HasPrefix(ext, ".") && !strings.ContainsAny(ext, "*?[") {
+				return ext
+			}
+		}
+	}
+	return ".txt"
+}
+
+// signature is a pattern hinting at a language, and how strongly
+type signature struct {
+	pattern *regexp.Regexp
+	weight  int
+}
+
+// minDetectScore is how much evidence detection needs before it guesses
+const minDetectScore = 3
+
+// signatures hold hints for the languages pasted most often. Analysers that
+// come with the lexers are too eager to be trusted with short snippets.
+var signatures = map[string][]signature{
+	"Go": {
+		{regexp.MustCompile(`(?m)^package \w+$`), 3},
+		{regexp.MustCompile(`(?m)^func (\(\w+ \*?\w+\) )?\w+\(`), 2},
+		{regexp.MustCompile(`:= `), 1},
+		{regexp.MustCompile(`(?m)^import \($`), 2},
+	},
+	"Python": {
+		{regexp.MustCompile(`(?m)^\s*def \w+\(.*\):\s*$`), 3},
+		{regexp.MustCompile(`(?m)^from [\w.]+ import `), 3},
+		{regexp.MustCompile(`(?m)^import \w+$`), 1},
+		{regexp.MustCompile(`(?m)^\s*class \w+(\(.*\))?:\s*$`), 2},
+		{regexp.MustCompile(`if __name__ == .__main__.:`), 3},
+		{regexp.MustCompile(`\bself\.`), 1},
+	},
+	"Bash": {
+		{regexp.MustCompile(`\A#!.*\b(ba|z)?sh\b`), 5},
+		{regexp.MustCompile(`(?m)^\s*(if|while) \[\[? .* \]\]?; (then|do)$`), 2},
+		{regexp.MustCompile(`(?m)^\s*(export|echo|sudo|apt-get|apt|yum|curl) `), 1},
+		{regexp.MustCompile(`\$\{?\w+\}?`), 1},
+		{regexp.MustCompile(`(?m)^\s*fi$`), 2},
+	},
+	"JavaScript": {
+		{regexp.MustCompile(`(?m)^\s*(const|let|var) \w+ = `), 1},
+		{regexp.MustCompile(`\bfunction\s*\w*\(`), 2},
+		{regexp.MustCompile(`=> \{`), 1},
+		{regexp.MustCompile(`\bconsole\.log\(`), 2},
+		{regexp.MustCompile(`\brequire\(['"]`), 2},
+		{regexp.MustCompile(`(?m)^(import .* from ['"]|export (default|const|function) )`), 2},
+	},
+	"TypeScript": {
+		{regexp.MustCompile(`(?m)^\s*(export )?interface \w+ \{`), 3},
+		{regexp.MustCompile(`(?m)^\s*(export )?type \w+ = `), 2},
+		{regexp.MustCompile(`\w+: (string|number|boolean)\b`), 2},
+	},
+	"Java": {
+		{regexp.MustCompile(`\bpublic (static )?(class|void|interface) `), 3},
+		{regexp.MustCompile(`\bSystem\.out\.print`), 3},
+		{regexp.MustCompile(`(?m)^import java\.`), 3},
+	},
+	"C": {
+		{regexp.MustCompile(`(?m)^#include [<"]`), 3},
+		{regexp.MustCompile(`\bint main\(`), 2},
+		{regexp.MustCompile(`\bprintf\(`), 1},
+	},
+	"C++": {
+		{regexp.MustCompile(`(?m)^#include <(iostream|vector|string|map)>`), 3},
+		{regexp.MustCompile(`\bstd::`), 3},
+		{regexp.MustCompile(`(?m)^using namespace `), 2},
+	},
+	"C#": {
+		{regexp.MustCompile(`(?m)^using System(\.\w+)*;`), 4},
+		{regexp.MustCompile(`\bConsole\.Write(Line)?\(`), 3},
+		{regexp.MustCompile(`(?m)^namespace [\w.]+`), 2},
+	},
+	"Rust": {
+		{regexp.MustCompile(`\bfn \w+\(.*\)( -> .+)? \{`), 3},
+		{regexp.MustCompile(`\blet mut `), 2},
+		{regexp.MustCompile(`(?m)^use (std|crate)::`), 3},
+		{regexp.MustCompile(`\bprintln!\(`), 2},
+	},
+	"PHP": {
+		{regexp.MustCompile(`<\?php`), 5},
+	},
+	"Ruby": {
+		{regexp.MustCompile(`(?m)^require ['"]`), 2},
+		{regexp.MustCompile(`(?m)^\s*def \w+[?!]?(\(.*\))?$`), 2},
+		{regexp.MustCompile(`(?m)^\s*end$`), 1},
+		{regexp.MustCompile(`\bputs `), 1},
+	},
+	"SQL": {
+		{regexp.MustCompile(`(?im)^\s*(select .+ from|insert into|delete from|create (table|index|view)|alter table)\b`), 3},
+		{regexp.MustCompile(`(?i)\b(where|group by|order by|inner join|left join)\b`), 1},
+	},
+	"YAML": {
+		{regexp.MustCompile(`(?m)^---$`), 1},
+		{regexp.MustCompile(`(?m)^[\w-]+:( |$)`), 1},
+		{regexp.MustCompile(`(?m)^[\w-]+:\n\s+[\w-]+: `), 2},
+		{regexp.MustCompile(`(?m)^\s+- [\w-]+: `), 2},
+	},
+	"XML": {
+		{regexp.MustCompile(`\A\s*<\?xml `), 5},
+	},
+	"HTML": {
+		{regexp.MustCompile(`(?i)<!DOCTYPE html>`), 5},
+		{regexp.MustCompile(`(?i)<(html|head|body|div|script)[ >]`), 2},
+	},
+	"Docker": {
+		{regexp.MustCompile(`(?m)^FROM \S+`), 3},
+		{regexp.MustCompile(`(?m)^(RUN|CMD|COPY|ENTRYPOINT|WORKDIR) `), 1},
+	},
+	"Diff": {
+		{regexp.MustCompile(`(?m)^diff --git `), 4},
+		{regexp.MustCompile(`(?m)^@@ -\d+(,\d+)? \+\d+(,\d+)? @@`), 4},
+	},
+	"INI": {
+		{regexp.MustCompile(`(?m)^\[[\w. -]+\]$`), 2},
+		{regexp.MustCompile(`(?m)^\w+\s*=\s*\S`), 1},
+	},
+	"Nginx configuration file": {
+		{regexp.MustCompile(`(?m)^\s*server \{`), 3},
+		{regexp.MustCompile(`(?m)^\s*(listen|server_name|proxy_pass|location) `), 2},
+	},
+	"markdown": {
+		{regexp.MustCompile(`(?m)^#{1,6} \S`), 2},
+		{regexp.MustCompile("(?m)^```"), 2},
+		{regexp.MustCompile(`\[[^\]]+\]\([^)]+\)`), 1},
+	},
+}
+
+// Detect guesses the language of a paste from its title, when that looks
+// like a file name, and from its content. It returns PlainText when nothing
+// fits well enough.
+func Detect(title, content string) string {
+	if lexer := lexers.Match(strings.TrimSpace(title)); lexer != nil {
+		return lexer.Config().Name
+	}
+
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return PlainText
+	}
+
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return "JSON"
+	}
+
+	best, bestScore := PlainText, minDetectScore-1
+	for language, hints := range signatures {
+		score := 0
+		for _, hint := range hints {
+			if hint.pattern.MatchString(content) {
+				score += hint.weight
+			}
+		}
+
+		// Ties go to the name sorting first, so detection is repeatable
+		if score > bestScore || (score == bestScore && best != PlainText && language < best) {
+			best, bestScore = language, score
+		}
+	}
+	return best
+}
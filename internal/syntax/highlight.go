@@ -0,0 +1,92 @@
+package syntax
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// Class is the kind of token a span of highlighted text holds
+type Class int
+
+const (
+	ClassText Class = iota
+	ClassKeyword
+	ClassName
+	ClassString
+	ClassNumber
+	ClassComment
+	ClassOperator
+)
+
+// Span is a run of text of one class
+type Span struct {
+	Text  string
+	Class Class
+}
+
+// Highlight splits content into lines of classified spans. Unknown languages
+// and content that fails to tokenize come back as plain text.
+func Highlight(content, language string) [][]Span {
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		return plainLines(content)
+	}
+
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, content)
+	if err != nil {
+		return plainLines(content)
+	}
+
+	lines := [][]Span{nil}
+	for _, token := range tokens.Tokens() {
+		class := classify(token.Type)
+
+		parts := strings.Split(token.Value, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				last := len(lines) - 1
+				lines[last] = append(lines[last], Span{Text: part, Class: class})
+			}
+		}
+	}
+
+	// Lexers end the content with a newline, which isn't a line of its own
+	if !strings.HasSuffix(content, "\n") && len(lines) > 1 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// plainLines splits content into lines of plain text
+func plainLines(content string) [][]Span {
+	var lines [][]Span
+	for _, line := range strings.Split(content, "\n") {
+		lines = append(lines, []Span{{Text: line, Class: ClassText}})
+	}
+	return lines
+}
+
+// classify maps a token type to the classes the viewer colors
+func classify(t chroma.TokenType) Class {
+	switch {
+	case t.InCategory(chroma.Comment):
+		return ClassComment
+	case t.InCategory(chroma.Keyword):
+		return ClassKeyword
+	case t.InSubCategory(chroma.LiteralString):
+		return ClassString
+	case t.InSubCategory(chroma.LiteralNumber):
+		return ClassNumber
+	case t.InCategory(chroma.Operator):
+		return ClassOperator
+	case t == chroma.NameFunction, t == chroma.NameClass, t == chroma.NameBuiltin, t == chroma.NameTag, t == chroma.NameAttribute:
+		return ClassName
+	default:
+		return ClassText
+	}
+}